@@ -0,0 +1,64 @@
+// Command scru64-coordinator runs a lease-based `nodeId` coordinator for SCRU64
+// ID generators.
+//
+// Usage:
+//
+//	scru64-coordinator [-addr :8064] [-node-id-size 8] [-ttl 30s] [-max-lead 10.256s]
+//
+// See package [github.com/scru64/go-scru64/lease] for the HTTP API and the
+// matching client.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/scru64/go-scru64/lease"
+)
+
+func main() {
+	addr := flag.String("addr", ":8064", "address to listen on")
+	nodeIdSize := flag.Uint("node-id-size", 8, "`nodeIdSize` of leased node IDs (1-23)")
+	ttl := flag.Duration("ttl", lease.DefaultTTL, "lifetime of a lease without renewal")
+	maxLead := flag.Duration("max-lead", lease.DefaultMaxLead,
+		"quarantine period of released and expired node IDs")
+	flag.Parse()
+
+	if *nodeIdSize > 0xff {
+		fmt.Fprintf(os.Stderr, "scru64-coordinator: invalid -node-id-size: %v\n", *nodeIdSize)
+		os.Exit(2)
+	}
+	s, err := lease.NewServer(uint8(*nodeIdSize), *ttl, *maxLead)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scru64-coordinator: %v\n", err)
+		os.Exit(2)
+	}
+
+	// lease state is not persisted, so refuse new leases until those granted
+	// before a restart have expired and left quarantine
+	s.WarmUpUntil = time.Now().Add(*ttl + *maxLead)
+	server := &http.Server{Addr: *addr, Handler: s}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("scru64-coordinator: listening on %v (nodeIdSize=%v, ttl=%v)",
+		*addr, *nodeIdSize, *ttl)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("scru64-coordinator: %v", err)
+	}
+}
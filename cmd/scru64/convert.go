@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/scru64/go-scru64"
+)
+
+// The notations supported by the convert command.
+var convertFormats = []string{"text", "int", "hex", "bytes"}
+
+// Converts IDs between notations.
+//
+//	scru64 convert --from text|int|hex|bytes --to text|int|hex|bytes
+//
+// The command reads IDs from the standard input and writes them to the
+// standard output in the following notations:
+//
+//   - text: the 12-digit canonical textual representation
+//   - int: the unsigned decimal integer (e.g., BIGINT columns)
+//   - hex: the hexadecimal integer, written as 16 lowercase digits
+//   - bytes: the 8-byte big-endian binary representation
+//
+// The text, int, and hex notations are line-oriented, ignoring surrounding
+// whitespace; the bytes notation consists of consecutive 8-byte records
+// without separators. Every value is strictly validated, and an invalid line or
+// record is reported with its number and produces no output, in which case the
+// command exits with status 1 after processing the entire input.
+func runConvert(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("convert", stderr)
+	from := fs.String("from", "text", "input notation: text, int, hex, or bytes")
+	to := fs.String("to", "text", "output notation: text, int, hex, or bytes")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "scru64 convert: unexpected argument %q\n", fs.Arg(0))
+		return 2
+	} else if !isFormat(*from, convertFormats) {
+		fmt.Fprintf(stderr, "scru64 convert: invalid --from: %q\n", *from)
+		return 2
+	} else if !isFormat(*to, convertFormats) {
+		fmt.Fprintf(stderr, "scru64 convert: invalid --to: %q\n", *to)
+		return 2
+	}
+
+	w := bufio.NewWriter(stdout)
+	write := func(id scru64.Id) {
+		if *to == "bytes" {
+			w.Write(binary.BigEndian.AppendUint64(nil, id.Num()))
+		} else {
+			w.WriteString(formatId(id, *to))
+			w.WriteByte('\n')
+		}
+	}
+
+	code := 0
+	r := bufio.NewReader(stdin)
+	if *from == "bytes" {
+		var record [8]byte
+		for i := 1; ; i++ {
+			n, err := io.ReadFull(r, record[:])
+			if err == io.EOF {
+				break
+			} else if errors.Is(err, io.ErrUnexpectedEOF) {
+				fmt.Fprintf(stderr, "scru64 convert: record %v: truncated to %v bytes\n", i, n)
+				code = 1
+				break
+			} else if err != nil {
+				fmt.Fprintf(stderr, "scru64 convert: %v\n", err)
+				return 1
+			}
+
+			id, err := scru64.FromUint(binary.BigEndian.Uint64(record[:]))
+			if err != nil {
+				fmt.Fprintf(stderr, "scru64 convert: record %v: %v\n", i, err)
+				code = 1
+				continue
+			}
+			write(id)
+		}
+	} else {
+		scanner := bufio.NewScanner(r)
+		for i := 1; scanner.Scan(); i++ {
+			id, err := parseIdAs(strings.TrimSpace(scanner.Text()), *from)
+			if err != nil {
+				fmt.Fprintf(stderr, "scru64 convert: line %v: %v\n", i, err)
+				code = 1
+				continue
+			}
+			write(id)
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(stderr, "scru64 convert: %v\n", err)
+			code = 1
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(stderr, "scru64 convert: %v\n", err)
+		return 1
+	}
+	return code
+}
+
+// Strictly parses an ID in the text, int, or hex notation.
+func parseIdAs(value string, format string) (scru64.Id, error) {
+	if format == "text" {
+		return scru64.Parse(value)
+	}
+
+	base := 10
+	if format == "hex" {
+		base = 16
+	}
+	n, err := strconv.ParseUint(value, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v value %q", format, value)
+	}
+	return scru64.FromUint(n)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Converts IDs between all the notations.
+func TestConvert(t *testing.T) {
+	inputs := map[string]string{
+		"text": "7lieexzx4kxs\n9ys742ms5v6r\n",
+		"int":  "1000000000000000000\n1311768467286393123\n",
+		"hex":  "0de0b6b3a7640000\n12345678902a0123\n",
+		"bytes": "\x0d\xe0\xb6\xb3\xa7\x64\x00\x00" +
+			"\x12\x34\x56\x78\x90\x2a\x01\x23",
+	}
+
+	for from, input := range inputs {
+		for to, want := range inputs {
+			if got, code := convertOnce(t, input, from, to); code != 0 || got != want {
+				t.Fatalf("%v to %v: got %v, %q (want %q)", from, to, code, got, want)
+			}
+		}
+	}
+}
+
+// Runs the convert command.
+func convertOnce(t *testing.T, input string, from string, to string) (string, int) {
+	t.Helper()
+	code, stdout, _ := runCommand(t, input, "convert", "--from", from, "--to", to)
+	return stdout, code
+}
+
+// Reports invalid lines and records with their numbers.
+func TestConvertInvalid(t *testing.T) {
+	code, stdout, stderr := runCommand(t,
+		"1311768467286393123\n-1\n\n9223372036854775807\n 42 \n",
+		"convert", "--from", "int", "--to", "hex")
+	if code != 1 || stdout != "12345678902a0123\n000000000000002a\n" {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+	for _, want := range []string{"line 2:", "line 3:", "line 4:"} {
+		if !strings.Contains(stderr, want) {
+			t.Fatalf("%q not in %q", want, stderr)
+		}
+	}
+
+	code, _, stderr = runCommand(t, "0x2a\n", "convert", "--from", "hex")
+	if code != 1 || !strings.Contains(stderr, "line 1:") {
+		t.Fatalf("got %v, %q", code, stderr)
+	}
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	input := "\xff\xff\xff\xff\xff\xff\xff\xff\x00\x00\x00\x00\x00\x00\x00\x2a\x00\x00"
+	code = run([]string{"convert", "--from", "bytes", "--to", "int"},
+		strings.NewReader(input), &stdoutBuf, &stderrBuf)
+	if code != 1 || stdoutBuf.String() != "42\n" ||
+		!strings.Contains(stderrBuf.String(), "record 1:") ||
+		!strings.Contains(stderrBuf.String(), "record 3:") {
+		t.Fatalf("got %v, %q, %q", code, stdoutBuf.String(), stderrBuf.String())
+	}
+
+	if code, _, _ := runCommand(t, "", "convert", "--to", "base64"); code != 2 {
+		t.Fatalf("got %v", code)
+	}
+}
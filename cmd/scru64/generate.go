@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/scru64/go-scru64"
+)
+
+// The output formats of IDs.
+var idFormats = []string{"text", "int", "hex", "json"}
+
+// Formats an ID in one of `idFormats`.
+func formatId(id scru64.Id, format string) string {
+	switch format {
+	case "int":
+		return strconv.FormatUint(id.Num(), 10)
+	case "hex":
+		return fmt.Sprintf("%016x", id.Num())
+	case "json":
+		data, _ := json.Marshal(struct {
+			Id  scru64.Id `json:"id"`
+			Int uint64    `json:"int"`
+			Hex string    `json:"hex"`
+		}{id, id.Num(), formatId(id, "hex")})
+		return string(data)
+	default:
+		return id.String()
+	}
+}
+
+// Returns whether `format` is one of `formats`.
+func isFormat(format string, formats []string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Generates IDs.
+//
+//	scru64 generate [--node-spec 42/8] [-n 1] [--format text|int|hex|json] [--stream]
+//
+// The IDs are generated by a single [scru64.Generator], so they are
+// monotonically increasing across the entire output. In the stream mode, the
+// command keeps writing one ID per line, flushing each line, until the output
+// is closed or the process is interrupted.
+func runGenerate(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("generate", stderr)
+	nodeSpecFlag := fs.String("node-spec", os.Getenv("SCRU64_NODE_SPEC"),
+		"node spec of the generator (default: $SCRU64_NODE_SPEC)")
+	count := fs.Int("n", 1, "number of IDs to generate")
+	format := fs.String("format", "text", "output format: text, int, hex, or json")
+	stream := fs.Bool("stream", false, "generate IDs continuously")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "scru64 generate: unexpected argument %q\n", fs.Arg(0))
+		return 2
+	} else if *nodeSpecFlag == "" {
+		fmt.Fprintf(stderr, "scru64 generate: --node-spec or SCRU64_NODE_SPEC required\n")
+		return 2
+	} else if *count < 0 {
+		fmt.Fprintf(stderr, "scru64 generate: invalid -n: %v\n", *count)
+		return 2
+	} else if !isFormat(*format, idFormats) {
+		fmt.Fprintf(stderr, "scru64 generate: invalid --format: %q\n", *format)
+		return 2
+	}
+	nodeSpec, err := scru64.ParseNodeSpec(*nodeSpecFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 generate: invalid --node-spec: %v\n", err)
+		return 2
+	}
+
+	g := scru64.NewGenerator(nodeSpec)
+	w := bufio.NewWriter(stdout)
+	for i := 0; *stream || i < *count; i++ {
+		w.WriteString(formatId(g.GenerateOrSleep(), *format))
+		w.WriteByte('\n')
+		if *stream {
+			if err := w.Flush(); err != nil {
+				// the reader has gone away
+				return 0
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(stderr, "scru64 generate: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Runs the command and returns the exit status and outputs.
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// Generates monotonically increasing IDs in each format.
+func TestGenerate(t *testing.T) {
+	code, stdout, _ := runCommand(t, "", "generate", "--node-spec", "42/8", "-n", "100")
+	lines := strings.Fields(stdout)
+	if code != 0 || len(lines) != 100 {
+		t.Fatalf("got %v, %v lines", code, len(lines))
+	}
+	for i, line := range lines {
+		x, err := scru64.Parse(line)
+		if err != nil || x.NodeCtr()>>16 != 42 || (i > 0 && line <= lines[i-1]) {
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+
+	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	for _, format := range []string{"int", "hex", "json"} {
+		code, stdout, _ := runCommand(t, "", "generate", "--format", format)
+		var x scru64.Id
+		var err error
+		switch format {
+		case "int":
+			var n uint64
+			n, err = strconv.ParseUint(strings.TrimSpace(stdout), 10, 64)
+			x, _ = scru64.FromUint(n)
+		case "hex":
+			var n uint64
+			n, err = strconv.ParseUint(strings.TrimSpace(stdout), 16, 64)
+			x, _ = scru64.FromUint(n)
+		case "json":
+			var v struct{ Id scru64.Id }
+			err = json.Unmarshal([]byte(stdout), &v)
+			x = v.Id
+		}
+		if code != 0 || err != nil || x.NodeCtr()>>16 != 42 {
+			t.Fatalf("unexpected %v output %q", format, stdout)
+		}
+	}
+}
+
+// A writer that fails after a number of writes.
+type failingWriter struct {
+	bytes.Buffer
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.remaining == 0 {
+		return 0, errors.New("closed")
+	}
+	w.remaining--
+	return w.Buffer.Write(p)
+}
+
+// Streams IDs until the output is closed.
+func TestGenerateStream(t *testing.T) {
+	w := &failingWriter{remaining: 5}
+	code := run([]string{"generate", "--node-spec", "42/8", "--stream"},
+		strings.NewReader(""), w, &bytes.Buffer{})
+	if lines := strings.Fields(w.String()); code != 0 || len(lines) != 5 {
+		t.Fatalf("got %v, %v lines", code, len(lines))
+	}
+}
+
+// Rejects invalid flags.
+func TestGenerateUsage(t *testing.T) {
+	t.Setenv("SCRU64_NODE_SPEC", "")
+	for _, args := range [][]string{
+		{"generate"},
+		{"generate", "--node-spec", "42"},
+		{"generate", "--node-spec", "42/8", "--format", "yaml"},
+		{"generate", "--node-spec", "42/8", "-n", "-1"},
+		{"generate", "--node-spec", "42/8", "extra"},
+		{"unknown"},
+		{},
+	} {
+		if code, _, stderr := runCommand(t, "", args...); code != 2 || stderr == "" {
+			t.Fatalf("%q: got %v", args, code)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Returns the current time; replaced in tests.
+var now = time.Now
+
+// The JSON output of the inspect command.
+type inspectOutput struct {
+	scru64.IdInfo
+	Int uint64 `json:"int"`
+	Hex string `json:"hex"`
+	Age string `json:"age"`
+}
+
+// See encoding/json.Marshaler
+//
+// This method appends the extra fields to the JSON object of the embedded
+// [scru64.IdInfo], whose `MarshalJSON` would otherwise hide them.
+func (o inspectOutput) MarshalJSON() ([]byte, error) {
+	info, err := json.Marshal(o.IdInfo)
+	if err != nil {
+		return nil, err
+	}
+	extra, err := json.Marshal(struct {
+		Int uint64 `json:"int"`
+		Hex string `json:"hex"`
+		Age string `json:"age"`
+	}{o.Int, o.Hex, o.Age})
+	if err != nil {
+		return nil, err
+	}
+	return append(append(info[:len(info)-1], ','), extra[1:]...), nil
+}
+
+// Decodes IDs.
+//
+//	scru64 inspect [--node-id-size 8] [--registry nodes.json] [--zone UTC] [--json] [id...]
+//
+// The command reads IDs from the arguments or, if none is given, from the
+// lines of the standard input. Each ID may be given in the canonical textual
+// representation, as a decimal integer, or as a hexadecimal integer prefixed
+// with "0x"; 12-character values are always read as the textual
+// representation. The `nodeId` and `counter` fields are shown if `--node-id-size` is
+// given or if the registry (see [scru64.NodeRegistry]) contains the node that
+// could have generated the ID.
+func runInspect(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("inspect", stderr)
+	nodeIdSize := fs.Uint("node-id-size", 0, "`nodeIdSize` to split nodeCtr into nodeId and counter")
+	registryPath := fs.String("registry", "", "path of node registry JSON file")
+	zone := fs.String("zone", "Local", "time zone name of the shown time (e.g., UTC, Asia/Tokyo)")
+	jsonOutput := fs.Bool("json", false, "print one JSON object per ID")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	if *nodeIdSize > 23 {
+		fmt.Fprintf(stderr, "scru64 inspect: invalid --node-id-size: %v\n", *nodeIdSize)
+		return 2
+	}
+	loc, err := time.LoadLocation(*zone)
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 inspect: invalid --zone: %v\n", err)
+		return 2
+	}
+	var registry *scru64.NodeRegistry
+	if *registryPath != "" {
+		if registry, err = scru64.LoadNodeRegistry(*registryPath); err != nil {
+			fmt.Fprintf(stderr, "scru64 inspect: %v\n", err)
+			return 1
+		}
+	}
+
+	w := bufio.NewWriter(stdout)
+	defer w.Flush()
+	code := 0
+	first := true
+	inspect := func(value string) {
+		id, err := parseIdArg(value)
+		if err != nil {
+			fmt.Fprintf(stderr, "scru64 inspect: %q: %v\n", value, err)
+			code = 1
+			return
+		}
+
+		info := id.Inspect(uint8(*nodeIdSize))
+		if registry != nil {
+			if found := registry.Inspect(id); found.Node != nil {
+				info = found
+			}
+		}
+		info.Time = info.Time.In(loc)
+		age := now().Sub(info.Time).Round(time.Millisecond)
+
+		if *jsonOutput {
+			data, _ := json.Marshal(inspectOutput{
+				IdInfo: info,
+				Int:    id.Num(),
+				Hex:    formatId(id, "hex"),
+				Age:    age.String(),
+			})
+			w.Write(data)
+			w.WriteByte('\n')
+			return
+		}
+
+		if !first {
+			w.WriteByte('\n')
+		}
+		first = false
+		fmt.Fprintf(w, "id:         %v\n", id)
+		fmt.Fprintf(w, "int:        %v\n", id.Num())
+		fmt.Fprintf(w, "hex:        %v\n", formatId(id, "hex"))
+		fmt.Fprintf(w, "timestamp:  %v\n", info.Timestamp)
+		fmt.Fprintf(w, "time:       %v\n", info.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+		fmt.Fprintf(w, "age:        %v\n", age)
+		fmt.Fprintf(w, "nodeCtr:    %v\n", info.NodeCtr)
+		if info.NodeIdSize > 0 {
+			fmt.Fprintf(w, "nodeIdSize: %v\n", info.NodeIdSize)
+			fmt.Fprintf(w, "nodeId:     %v\n", info.NodeId)
+			fmt.Fprintf(w, "counter:    %v\n", info.Counter)
+		}
+		if info.Node != nil {
+			fmt.Fprintf(w, "node:       %v (%v)\n", info.Node.Name, info.Node.Nodes)
+		}
+	}
+
+	if fs.NArg() > 0 {
+		for _, arg := range fs.Args() {
+			inspect(arg)
+		}
+		return code
+	}
+
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			inspect(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "scru64 inspect: %v\n", err)
+		return 1
+	}
+	return code
+}
+
+// Parses an ID given in the canonical textual representation, as a decimal
+// integer, or as a hexadecimal integer prefixed with "0x".
+func parseIdArg(value string) (scru64.Id, error) {
+	if len(value) == 12 {
+		return scru64.Parse(value)
+	}
+	var n uint64
+	var err error
+	if hex, ok := strings.CutPrefix(value, "0x"); ok {
+		n, err = strconv.ParseUint(hex, 16, 64)
+	} else {
+		n, err = strconv.ParseUint(value, 10, 64)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("not an ID in text, integer, or 0x-prefixed hex form")
+	}
+	return scru64.FromUint(n)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Decodes IDs given as arguments.
+func TestInspect(t *testing.T) {
+	x, _ := scru64.FromParts(0x1234567890, 42<<16|0x0123)
+	now = func() time.Time { return x.Time().Add(90 * time.Second) }
+	t.Cleanup(func() { now = time.Now })
+
+	code, stdout, _ := runCommand(t, "", "inspect", "--zone", "UTC", "--node-id-size", "8", x.String())
+	for _, want := range []string{
+		"id:         " + x.String(),
+		"hex:        12345678902a0123",
+		"timestamp:  78187493520",
+		"time:       " + x.Time().UTC().Format("2006-01-02T15:04:05.000Z"),
+		"age:        1m30s",
+		"nodeCtr:    2752803",
+		"nodeId:     42",
+		"counter:    291",
+	} {
+		if code != 0 || !strings.Contains(stdout, want+"\n") {
+			t.Fatalf("%q not in %q", want, stdout)
+		}
+	}
+
+	// integer forms, without nodeIdSize
+	code, stdout, _ = runCommand(t, "", "inspect", "0x12345678902a0123", "1311768467286393123")
+	if code != 0 || strings.Count(stdout, "id:         "+x.String()) != 2 ||
+		strings.Contains(stdout, "nodeId:") {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+}
+
+// Reads IDs from stdin and annotates them with registered nodes.
+func TestInspectRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.json")
+	os.WriteFile(path, []byte(`[{"nodes": "40-47/8", "name": "ingest"}]`), 0o644)
+	x, _ := scru64.FromParts(0x1234567890, 42<<16|0x0123)
+	y, _ := scru64.FromParts(0x1234567890, 48<<16|0x0123)
+
+	code, stdout, stderr := runCommand(t, x.String()+"\n\ninvalid\n"+y.String()+"\n",
+		"inspect", "--registry", path, "--json")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if code != 1 || len(lines) != 2 || !strings.Contains(stderr, `"invalid"`) {
+		t.Fatalf("got %v, %q, %q", code, stdout, stderr)
+	}
+
+	var v inspectOutput
+	if err := json.Unmarshal([]byte(lines[0]), &v); err != nil || v.Id != x ||
+		v.Int != x.Num() || v.Node == nil || v.Node.Name != "ingest" || v.NodeId != 42 {
+		t.Fatalf("unexpected output %q", lines[0])
+	}
+	v = inspectOutput{}
+	if err := json.Unmarshal([]byte(lines[1]), &v); err != nil || v.Id != y ||
+		v.Node != nil || v.NodeIdSize != 0 {
+		t.Fatalf("unexpected output %q", lines[1])
+	}
+
+	// zero nodeId is shown when nodeIdSize is known
+	z, _ := scru64.FromParts(0x1234567890, 5)
+	code, stdout, _ = runCommand(t, "", "inspect", "--node-id-size", "8", "--json", z.String())
+	if code != 0 || !strings.Contains(stdout, `"nodeIdSize":8,"nodeId":0,"counter":5`) ||
+		!strings.Contains(stdout, `"hex":"1234567890000005"`) {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+
+	for _, args := range [][]string{
+		{"inspect", "--node-id-size", "24"},
+		{"inspect", "--zone", "Nowhere/Unknown"},
+	} {
+		if code, _, _ := runCommand(t, "", args...); code != 2 {
+			t.Fatalf("%q: got %v", args, code)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Command scru64 generates and examines SCRU64 IDs.
+//
+// Usage:
+//
+//	scru64 <command> [flags] [args]
+//
+// The commands are:
+//
+//	generate    generate IDs
+//	inspect     decode IDs
+//	convert     convert IDs between notations
+//	plan        estimate capacity and recommend nodeIdSize
+//	verify      check IDs for validity, uniqueness, and order
+//	serve       serve IDs over HTTP
+//	range       print ID bounds for a time window
+//	nodespec    explain, split, and check node specs
+//
+// Run "scru64 <command> -h" for the flags of each command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Represents a subcommand.
+type command struct {
+	name     string
+	synopsis string
+
+	// Runs the subcommand with the arguments following the subcommand name and
+	// returns the exit status.
+	run func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int
+}
+
+// The list of subcommands in the order shown in the usage message.
+var commands = []command{
+	{"generate", "generate IDs", runGenerate},
+	{"inspect", "decode IDs", runInspect},
+	{"convert", "convert IDs between notations", runConvert},
+	{"plan", "estimate capacity and recommend nodeIdSize", runPlan},
+	{"verify", "check IDs for validity, uniqueness, and order", runVerify},
+	{"serve", "serve IDs over HTTP", runServe},
+	{"range", "print ID bounds for a time window", runRange},
+	{"nodespec", "explain, split, and check node specs", runNodeSpec},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Dispatches `args` to a subcommand and returns the exit status: 0 on success,
+// 1 on runtime errors, and 2 on usage errors.
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return 2
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "scru64: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+// Prints the list of subcommands.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: scru64 <command> [flags] [args]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10v  %v\n", c.name, c.synopsis)
+	}
+}
+
+// Creates a flag set for a subcommand that reports errors to `stderr`.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("scru64 "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// Parses the flags of a subcommand and returns the exit status to return
+// immediately, or -1 to continue.
+func parseFlags(fs *flag.FlagSet, args []string) int {
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	return -1
+}
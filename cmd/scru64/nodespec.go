@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Explains, splits, and checks node specs.
+//
+//	scru64 nodespec <spec>
+//	scru64 nodespec split --bits 2 <spec>
+//	scru64 nodespec check [spec...]
+//
+// The default mode explains a node spec: its `nodeId`, `nodeIdSize`,
+// `counterSize`, `nodePrev` (if any), the number of IDs per tick, and the range
+// of IDs that a node with the spec can generate. The split mode lists the
+// `2^bits` child specs that divide the node's range (see
+// [scru64.NodeSpec.Split]). The check mode reads node specs or node spec sets
+// (e.g., "40-47/8") from the arguments or, if none is given, from the lines of
+// the standard input, and exits with status 1 if any two of them overlap.
+func runNodeSpec(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "split":
+			return runNodeSpecSplit(args[1:], stdout, stderr)
+		case "check":
+			return runNodeSpecCheck(args[1:], stdin, stdout, stderr)
+		}
+	}
+
+	fs := newFlagSet("nodespec", stderr)
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(stderr, "scru64 nodespec: expected one node spec\n")
+		return 2
+	}
+	n, err := scru64.ParseNodeSpec(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 nodespec: %v\n", err)
+		return 1
+	}
+
+	counterSize := 24 - n.NodeIdSize()
+	lo := n.NodeId() << counterSize
+	hi := lo | (1<<counterSize - 1)
+	minId, _ := scru64.FromParts(0, lo)
+	maxId, err := scru64.FromParts(scru64.MaxId.Timestamp(), hi)
+	if err != nil {
+		// the last tick is partially available
+		maxId, _ = scru64.FromParts(scru64.MaxId.Timestamp()-1, hi)
+	}
+
+	fmt.Fprintf(stdout, "nodeSpec:     %v\n", n)
+	fmt.Fprintf(stdout, "nodeId:       %v (0x%x)\n", n.NodeId(), n.NodeId())
+	fmt.Fprintf(stdout, "nodeIdSize:   %v\n", n.NodeIdSize())
+	fmt.Fprintf(stdout, "counterSize:  %v\n", counterSize)
+	if prev := n.NodePrev(); prev != 0 {
+		fmt.Fprintf(stdout, "nodePrev:     %v (%v)\n",
+			prev, prev.Time().UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	}
+	fmt.Fprintf(stdout, "IDs per tick: %v (%v per second)\n",
+		uint32(1)<<counterSize, uint64(1)<<counterSize*1000/256)
+	fmt.Fprintf(stdout, "nodeCtr:      %v-%v\n", lo, hi)
+	fmt.Fprintf(stdout, "IDs:          %v-%v\n", minId, maxId)
+	return 0
+}
+
+// Lists the child specs of a node spec.
+func runNodeSpecSplit(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("nodespec split", stderr)
+	extraBits := fs.Uint("bits", 1, "number of `nodeId` bits to add")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(stderr, "scru64 nodespec split: expected one node spec\n")
+		return 2
+	} else if *extraBits == 0 || *extraBits > 22 {
+		fmt.Fprintf(stderr, "scru64 nodespec split: invalid --bits: %v\n", *extraBits)
+		return 2
+	}
+	n, err := scru64.ParseNodeSpec(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 nodespec split: %v\n", err)
+		return 1
+	}
+	children, err := n.Split(uint8(*extraBits))
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 nodespec split: %v\n", err)
+		return 1
+	}
+
+	w := bufio.NewWriter(stdout)
+	defer w.Flush()
+	for _, child := range children {
+		fmt.Fprintln(w, child)
+	}
+	return 0
+}
+
+// Checks that node specs and node spec sets do not overlap.
+func runNodeSpecCheck(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("nodespec check", stderr)
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	values := fs.Args()
+	if len(values) == 0 {
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				values = append(values, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(stderr, "scru64 nodespec check: %v\n", err)
+			return 1
+		}
+	}
+
+	problems := 0
+	registry := scru64.NewNodeRegistry()
+	for _, value := range values {
+		set, err := parseNodeSpecOrSet(value)
+		if err != nil {
+			fmt.Fprintf(stdout, "invalid: %v\n", err)
+			problems++
+		} else if err := registry.Register(scru64.NodeRegistryEntry{Nodes: set, Name: value}); err != nil {
+			fmt.Fprintf(stdout, "overlap: %v\n", err)
+			problems++
+		}
+	}
+	fmt.Fprintf(stderr, "scru64 nodespec check: %v specs, %v problems\n", len(values), problems)
+	if problems > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Parses a node spec in any syntax accepted by [scru64.ParseNodeSpec] or a
+// node spec set string.
+func parseNodeSpecOrSet(value string) (scru64.NodeSpecSet, error) {
+	if n, err := scru64.ParseNodeSpec(value); err == nil {
+		return scru64.NewNodeSpecSet(n.NodeId(), n.NodeId(), n.NodeIdSize())
+	}
+	return scru64.ParseNodeSpecSet(value)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Explains node specs.
+func TestNodeSpec(t *testing.T) {
+	code, stdout, _ := runCommand(t, "", "nodespec", "0u2r85hm2pt3/16")
+	for _, want := range []string{
+		"nodeId:       11001 (0x2af9)\n",
+		"nodeIdSize:   16\n",
+		"counterSize:  8\n",
+		"nodePrev:     0u2r85hm2pt3 (2023-03-04T11:04:46.080Z)\n",
+		"IDs per tick: 256 (1000 per second)\n",
+		"nodeCtr:      2816256-2816511\n",
+		"IDs:          00000001od1c-zzzzzzzrorun\n",
+	} {
+		if code != 0 || !strings.Contains(stdout, want) {
+			t.Fatalf("%q not in %q", want, stdout)
+		}
+	}
+
+	code, stdout, _ = runCommand(t, "", "nodespec", "42/8")
+	if code != 0 || strings.Contains(stdout, "nodePrev") {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+	for _, args := range [][]string{{"nodespec"}, {"nodespec", "42/8", "43/8"}} {
+		if code, _, _ := runCommand(t, "", args...); code != 2 {
+			t.Fatalf("%q: got %v", args, code)
+		}
+	}
+	if code, _, _ := runCommand(t, "", "nodespec", "42"); code != 1 {
+		t.Fatalf("got %v", code)
+	}
+}
+
+// Lists child specs.
+func TestNodeSpecSplit(t *testing.T) {
+	code, stdout, _ := runCommand(t, "", "nodespec", "split", "--bits", "2", "0x2a/8")
+	if code != 0 || stdout != "168/10\n169/10\n170/10\n171/10\n" {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+	if code, _, _ := runCommand(t, "", "nodespec", "split", "--bits", "16", "42/8"); code != 1 {
+		t.Fatalf("got %v", code)
+	}
+	if code, _, _ := runCommand(t, "", "nodespec", "split", "--bits", "0", "42/8"); code != 2 {
+		t.Fatalf("got %v", code)
+	}
+}
+
+// Detects overlapping specs.
+func TestNodeSpecCheck(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "", "nodespec", "check", "40-47/8", "48/8", "0x300/10")
+	if code != 0 || stdout != "" || !strings.Contains(stderr, "3 specs, 0 problems") {
+		t.Fatalf("got %v, %q, %q", code, stdout, stderr)
+	}
+
+	code, stdout, _ = runCommand(t, "40-47/8\n0x2a1/12\n\n1/3\n48/8\ninvalid\n", "nodespec", "check")
+	if code != 1 || strings.Count(stdout, "overlap: ") != 2 ||
+		strings.Count(stdout, "invalid: ") != 1 || strings.Contains(stdout, `"48/8"`) {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+}
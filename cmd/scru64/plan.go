@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Estimates the capacity of a workload and recommends a `nodeIdSize`.
+//
+//	scru64 plan --nodes 100 --rate 1000 [--burst 1s] [--overflow-guard 0] [--no-overflow-guard] [--node-id-size 0] [--json]
+//
+// See [scru64.Plan] for the meaning of the estimates.
+func runPlan(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("plan", stderr)
+	nodes := fs.Int("nodes", 0, "number of nodes generating IDs concurrently")
+	rate := fs.Float64("rate", 0, "expected peak IDs per second per node")
+	burst := fs.Duration("burst", time.Second, "duration for which each node sustains the peak rate")
+	overflowGuard := fs.Uint("overflow-guard", 0,
+		"overflow guard size of the counter mode (0: the default of NewGenerator)")
+	noOverflowGuard := fs.Bool("no-overflow-guard", false, "evaluate the counter mode without the overflow guard")
+	nodeIdSize := fs.Uint("node-id-size", 0, "`nodeIdSize` to evaluate (0: the recommended one)")
+	jsonOutput := fs.Bool("json", false, "print the plan as JSON")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "scru64 plan: unexpected argument %q\n", fs.Arg(0))
+		return 2
+	} else if *overflowGuard > 0xff {
+		fmt.Fprintf(stderr, "scru64 plan: invalid --overflow-guard: %v\n", *overflowGuard)
+		return 2
+	} else if *nodeIdSize > 0xff {
+		fmt.Fprintf(stderr, "scru64 plan: invalid --node-id-size: %v\n", *nodeIdSize)
+		return 2
+	}
+	p, err := scru64.Plan(scru64.PlanOptions{
+		NodeCount:         *nodes,
+		PeakRate:          *rate,
+		BurstDuration:     *burst,
+		OverflowGuardSize: uint8(*overflowGuard),
+		NoOverflowGuard:   *noOverflowGuard,
+		NodeIdSize:        uint8(*nodeIdSize),
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 plan: %v\n", err)
+		return 2
+	}
+
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(p, "", "  ")
+		fmt.Fprintf(stdout, "%s\n", data)
+		return 0
+	}
+	fmt.Fprintf(stdout, "nodeIdSize:           %v (max %v nodes)\n", p.NodeIdSize, p.MaxNodes)
+	fmt.Fprintf(stdout, "counterSize:          %v (overflow guard %v)\n", p.CounterSize, p.OverflowGuardSize)
+	fmt.Fprintf(stdout, "IDs per tick:         %.1f\n", p.IdsPerTick)
+	fmt.Fprintf(stdout, "tick capacity:        %v min, %.1f mean\n", p.MinTickCapacity, p.MeanTickCapacity)
+	fmt.Fprintf(stdout, "borrow rate:          %.4g%%\n", p.BorrowRate*100)
+	fmt.Fprintf(stdout, "expected lead:        %v\n", p.ExpectedLead)
+	fmt.Fprintf(stdout, "sustainable:          %v\n", p.Sustainable)
+	fmt.Fprintf(stdout, "reset duplicate risk: %.4g%% per rewound tick\n", p.ResetDuplicateRisk*100)
+	fmt.Fprintf(stdout, "recommended:          nodeIdSize %v (e.g., node spec \"0/%v\")\n",
+		p.RecommendedNodeIdSize, p.RecommendedNodeIdSize)
+	return 0
+}
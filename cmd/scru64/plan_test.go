@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Prints the capacity plan.
+func TestPlan(t *testing.T) {
+	code, stdout, _ := runCommand(t, "", "plan", "--nodes", "100", "--rate", "1000")
+	if code != 0 || !strings.Contains(stdout, "nodeIdSize:           9 (max 512 nodes)\n") ||
+		!strings.Contains(stdout, "recommended:          nodeIdSize 9") ||
+		!strings.Contains(stdout, "expected lead:        ") {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+
+	code, stdout, _ = runCommand(t, "", "plan", "--nodes", "100", "--rate", "1000",
+		"--node-id-size", "8", "--json")
+	var p scru64.CapacityPlan
+	if err := json.Unmarshal([]byte(stdout), &p); code != 0 || err != nil ||
+		p.NodeIdSize != 8 || p.RecommendedNodeIdSize != 9 || p.IdsPerTick != 256 {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+
+	code, stdout, _ = runCommand(t, "", "plan", "--nodes", "1", "--rate", "1",
+		"--node-id-size", "21", "--json")
+	if err := json.Unmarshal([]byte(stdout), &p); code != 0 || err != nil || p.OverflowGuardSize != 1 {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+	p = scru64.CapacityPlan{}
+	code, stdout, _ = runCommand(t, "", "plan", "--nodes", "1", "--rate", "1",
+		"--node-id-size", "21", "--no-overflow-guard", "--json")
+	if err := json.Unmarshal([]byte(stdout), &p); code != 0 || err != nil || p.OverflowGuardSize != 0 {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+
+	for _, args := range [][]string{
+		{"plan"},
+		{"plan", "--nodes", "300", "--rate", "1", "--node-id-size", "8"},
+		{"plan", "--nodes", "1", "--rate", "1", "--node-id-size", "256"},
+		{"plan", "--nodes", "1", "--rate", "1", "--overflow-guard", "256"},
+	} {
+		if code, _, stderr := runCommand(t, "", args...); code != 2 || stderr == "" {
+			t.Fatalf("%q: got %v", args, code)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Prints the smallest and largest IDs that can be generated within a time
+// window.
+//
+//	scru64 range --from <time> --to <time> [--sql column]
+//
+// Each time may be given in the RFC 3339 format (e.g.,
+// "2024-06-01T10:00:00+09:00"), as a duration relative to the current time
+// (e.g., "-1h30m", "now"), or as a Unix timestamp in milliseconds (e.g.,
+// "1717203600000"). The window includes `--from` and excludes `--to`.
+//
+// Because the `timestamp` field has a resolution of 256 milliseconds, the
+// bounds cover every tick that overlaps the window, so they may include IDs
+// generated up to 255 milliseconds before `--from` or after `--to`. With
+// `--sql`, the command also prints SQL predicates on the column for both the
+// textual and integer representations.
+func runRange(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("range", stderr)
+	fromFlag := fs.String("from", "", "start of the window (inclusive)")
+	toFlag := fs.String("to", "now", "end of the window (exclusive)")
+	column := fs.String("sql", "", "column name for SQL predicates")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "scru64 range: unexpected argument %q\n", fs.Arg(0))
+		return 2
+	}
+	current := now()
+	from, err := parseTimeArg(*fromFlag, current)
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 range: invalid --from: %v\n", err)
+		return 2
+	}
+	to, err := parseTimeArg(*toFlag, current)
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 range: invalid --to: %v\n", err)
+		return 2
+	}
+
+	minId, maxId, err := idRange(from, to)
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 range: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "from: %v\n", from.Format(time.RFC3339Nano))
+	fmt.Fprintf(stdout, "to:   %v\n", to.Format(time.RFC3339Nano))
+	fmt.Fprintf(stdout, "min:  %v %v\n", minId, minId.Num())
+	fmt.Fprintf(stdout, "max:  %v %v\n", maxId, maxId.Num())
+	if *column != "" {
+		fmt.Fprintf(stdout, "sql:  %v BETWEEN '%v' AND '%v'\n", *column, minId, maxId)
+		fmt.Fprintf(stdout, "sql:  %v BETWEEN %v AND %v\n", *column, minId.Num(), maxId.Num())
+	}
+	return 0
+}
+
+// Parses a time given in the RFC 3339 format, as a duration relative to `now`,
+// or as a Unix timestamp in milliseconds.
+func parseTimeArg(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("required")
+	} else if value == "now" {
+		return now, nil
+	} else if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	} else if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	} else if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		d, err := time.ParseDuration(value)
+		if err == nil {
+			return now.Add(d), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(
+		"%q is not an RFC 3339 time, signed duration, or Unix milliseconds", value)
+}
+
+// Returns the smallest and largest IDs whose `timestamp` ticks overlap the
+// window from `from` (inclusive) to `to` (exclusive).
+func idRange(from time.Time, to time.Time) (scru64.Id, scru64.Id, error) {
+	// a tick T covers [T << 8, (T + 1) << 8) in Unix milliseconds; sub-millisecond
+	// parts of `to` make the millisecond partially covered
+	fromMs := from.UnixMilli()
+	toMs := to.UnixMilli()
+	if to.Sub(time.UnixMilli(toMs)) > 0 {
+		toMs++
+	}
+	if toMs <= fromMs {
+		return 0, 0, fmt.Errorf("empty window: %v to %v", from, to)
+	} else if fromMs < 0 {
+		return 0, 0, fmt.Errorf("window starts before Unix epoch: %v", from)
+	}
+
+	minTick := uint64(fromMs) >> 8
+	maxTick := uint64(toMs-1) >> 8
+	minId, err := scru64.FromParts(minTick, 0)
+	if err != nil {
+		return 0, 0, fmt.Errorf("window out of range: %w", err)
+	}
+	maxId, err := scru64.FromParts(maxTick, 1<<24-1)
+	if err != nil {
+		return 0, 0, fmt.Errorf("window out of range: %w", err)
+	}
+	return minId, maxId, nil
+}
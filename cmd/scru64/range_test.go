@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Rounds the window to the overlapping ticks.
+func TestIdRange(t *testing.T) {
+	tick := func(n int64) time.Time { return time.UnixMilli(n << 8) }
+	cases := []struct {
+		from, to         time.Time
+		minTick, maxTick uint64
+	}{
+		{tick(1000), tick(1001), 1000, 1000},
+		{tick(1000), tick(1001).Add(time.Millisecond), 1000, 1001},
+		{tick(1000).Add(255 * time.Millisecond), tick(1001), 1000, 1000},
+		{tick(1000).Add(-time.Millisecond), tick(1001), 999, 1000},
+		{tick(1000), tick(1001).Add(time.Microsecond), 1000, 1001},
+	}
+	for _, e := range cases {
+		minId, maxId, err := idRange(e.from, e.to)
+		if err != nil || minId.Timestamp() != e.minTick || minId.NodeCtr() != 0 ||
+			maxId.Timestamp() != e.maxTick || maxId.NodeCtr() != 1<<24-1 {
+			t.Fatalf("%v - %v: got %v, %v, %v", e.from, e.to, minId, maxId, err)
+		}
+	}
+
+	if _, _, err := idRange(tick(1000), tick(1000)); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// Accepts RFC 3339, relative, and Unix millisecond times.
+func TestRange(t *testing.T) {
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return base.Add(time.Hour) }
+	t.Cleanup(func() { now = time.Now })
+
+	minId, _ := scru64.FromParts(uint64(base.UnixMilli())>>8, 0)
+	maxId, _ := scru64.FromParts(uint64(base.Add(time.Hour).UnixMilli()-1)>>8, 1<<24-1)
+	for _, args := range [][]string{
+		{"range", "--from", "2024-06-01T19:00:00+09:00", "--to", "2024-06-01T11:00:00Z"},
+		{"range", "--from", "-1h"},
+		{"range", "--from", "1717236000000", "--to", "now"},
+	} {
+		code, stdout, _ := runCommand(t, "", append(args, "--sql", "id")...)
+		for _, want := range []string{
+			"from: 2024-06-01T",
+			"min:  " + minId.String() + " ",
+			"max:  " + maxId.String() + " ",
+			"sql:  id BETWEEN '" + minId.String() + "' AND '" + maxId.String() + "'\n",
+		} {
+			if code != 0 || !strings.Contains(stdout, want) {
+				t.Fatalf("%q: %q not in %q", args, want, stdout)
+			}
+		}
+	}
+
+	for _, args := range [][]string{
+		{"range"},
+		{"range", "--from", "yesterday"},
+		{"range", "--from", "-1h", "--to", "1h"},
+	} {
+		if code, _, _ := runCommand(t, "", args...); code != 2 {
+			t.Fatalf("%q: got %v", args, code)
+		}
+	}
+	if code, _, _ := runCommand(t, "", "range", "--from", "now", "--to", "-1h"); code != 1 {
+		t.Fatal("expected error for empty window")
+	}
+}
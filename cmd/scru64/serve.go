@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/scru64/go-scru64"
+	"github.com/scru64/go-scru64/idservice"
+)
+
+// Returns a context canceled on SIGINT or SIGTERM; replaced in tests.
+var notifyShutdown = func() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// Wraps the handler of the server; replaced in tests.
+var wrapHandler = func(h http.Handler) http.Handler { return h }
+
+// Serves IDs over HTTP.
+//
+//	scru64 serve [--addr :8080] [--node-spec 42/8] [--max-batch 10000]
+//
+// See package [github.com/scru64/go-scru64/idservice] for the endpoints. The
+// server shuts down gracefully on SIGINT or SIGTERM.
+func runServe(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("serve", stderr)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	nodeSpecFlag := fs.String("node-spec", os.Getenv("SCRU64_NODE_SPEC"),
+		"node spec of the generator (default: $SCRU64_NODE_SPEC)")
+	maxBatch := fs.Int("max-batch", idservice.DefaultMaxBatch, "maximum number of IDs per batch request")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "scru64 serve: unexpected argument %q\n", fs.Arg(0))
+		return 2
+	} else if *nodeSpecFlag == "" {
+		fmt.Fprintf(stderr, "scru64 serve: --node-spec or SCRU64_NODE_SPEC required\n")
+		return 2
+	} else if *maxBatch < 1 {
+		fmt.Fprintf(stderr, "scru64 serve: invalid --max-batch: %v\n", *maxBatch)
+		return 2
+	}
+	nodeSpec, err := scru64.ParseNodeSpec(*nodeSpecFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "scru64 serve: invalid --node-spec: %v\n", err)
+		return 2
+	}
+
+	s := idservice.NewServer(scru64.NewGenerator(nodeSpec))
+	s.MaxBatch = *maxBatch
+	server := &http.Server{Addr: *addr, Handler: wrapHandler(s)}
+
+	ctx, stop := notifyShutdown()
+	defer stop()
+	shutdownErr := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		shutdownErr <- server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(stderr, "scru64 serve: listening on %v (node spec %v)\n", *addr, nodeSpec)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "scru64 serve: %v\n", err)
+		return 1
+	}
+
+	// ListenAndServe returns as soon as Shutdown starts, so wait for in-flight
+	// requests to drain
+	code := 0
+	if err := <-shutdownErr; err != nil {
+		fmt.Fprintf(stderr, "scru64 serve: could not shut down gracefully: %v\n", err)
+		code = 1
+	}
+	stats := s.Stats()
+	fmt.Fprintf(stderr, "scru64 serve: shut down after %v requests, %v IDs\n",
+		stats.Requests, stats.IdsGenerated)
+	return code
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Rejects invalid flags before listening.
+func TestServeUsage(t *testing.T) {
+	t.Setenv("SCRU64_NODE_SPEC", "")
+	for _, args := range [][]string{
+		{"serve"},
+		{"serve", "--node-spec", "42"},
+		{"serve", "--node-spec", "42/8", "--max-batch", "0"},
+	} {
+		if code, _, stderr := runCommand(t, "", args...); code != 2 || stderr == "" {
+			t.Fatalf("%q: got %v", args, code)
+		}
+	}
+
+	if code, _, _ := runCommand(t, "", "serve", "--node-spec", "42/8", "--addr", "invalid:addr:x"); code != 1 {
+		t.Fatalf("got %v", code)
+	}
+}
+
+// Finishes in-flight requests before returning on shutdown.
+func TestServeDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	entered, release := make(chan struct{}), make(chan struct{})
+	origNotify, origWrap := notifyShutdown, wrapHandler
+	notifyShutdown = func() (context.Context, context.CancelFunc) { return ctx, cancel }
+	wrapHandler = func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(entered)
+			<-release
+			h.ServeHTTP(w, r)
+		})
+	}
+	t.Cleanup(func() { notifyShutdown, wrapHandler = origNotify, origWrap })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	type result struct {
+		code   int
+		stderr string
+	}
+	done := make(chan result, 1)
+	go func() {
+		code, _, stderr := runCommand(t, "", "serve", "--node-spec", "42/8", "--addr", addr)
+		done <- result{code, stderr}
+	}()
+
+	var conn net.Conn
+	for i := 0; conn == nil; i++ {
+		if conn, err = net.Dial("tcp", addr); err != nil && i == 500 {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer conn.Close()
+
+	// shut down while a request is in the handler
+	fmt.Fprint(conn, "GET /id HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	<-entered
+	cancel()
+	select {
+	case r := <-done:
+		t.Fatalf("returned before in-flight request completed: %v, %q", r.code, r.stderr)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	r := <-done
+	if r.code != 0 || !strings.Contains(r.stderr, "shut down after 1 requests, 1 IDs") {
+		t.Fatalf("got %v, %q", r.code, r.stderr)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/scru64/go-scru64"
+)
+
+// The location of a previously read ID.
+type verifyEntry struct {
+	id   scru64.Id
+	line int
+}
+
+// Checks that the IDs in a file are valid, unique, and sorted.
+//
+//	scru64 verify [--format text|int|hex] [--node-id-size 8] [--window 0] [--window-limit 1000000] [file]
+//
+// The command reads one ID per line from the file or, if no file or "-" is
+// given, from the standard input, and reports the following problems to the
+// standard output with line numbers:
+//
+//   - invalid lines that do not hold an ID in the given notation
+//   - duplicates of previously read IDs
+//   - out-of-order IDs that are smaller than the preceding ID
+//
+// If `--node-id-size` is given, the IDs are grouped by the decoded `nodeId` and
+// the order is checked within each group, which suits the output of multiple
+// generators merged together. The command exits with status 1 if it finds any
+// problem and prints a summary to the standard error.
+//
+// To keep memory usage bounded, the command streams the input and remembers
+// only the last ID of each group by default, which finds all duplicates in
+// input sorted within each group. For unsorted input, `--window` makes the
+// command also remember the IDs within that many ticks of the largest
+// `timestamp` read so far, up to `--window-limit` IDs, so that it finds the
+// duplicates that lie within the window of each other. Because the number of
+// IDs per tick depends on the generation rate, the command stops remembering
+// new IDs once the limit is reached and reports that in the summary.
+func runVerify(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := newFlagSet("verify", stderr)
+	format := fs.String("format", "text", "input notation: text, int, or hex")
+	nodeIdSize := fs.Uint("node-id-size", 0, "`nodeIdSize` to group IDs by nodeId (0: no grouping)")
+	window := fs.Uint64("window", 0,
+		"number of recent 256ms ticks to check for duplicates in unsorted input (0: disabled)")
+	windowLimit := fs.Int("window-limit", 1_000_000, "maximum number of IDs to remember for --window")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	if fs.NArg() > 1 {
+		fmt.Fprintf(stderr, "scru64 verify: unexpected argument %q\n", fs.Arg(1))
+		return 2
+	} else if !isFormat(*format, []string{"text", "int", "hex"}) {
+		fmt.Fprintf(stderr, "scru64 verify: invalid --format: %q\n", *format)
+		return 2
+	} else if *nodeIdSize > 23 {
+		fmt.Fprintf(stderr, "scru64 verify: invalid --node-id-size: %v\n", *nodeIdSize)
+		return 2
+	} else if *windowLimit <= 0 {
+		fmt.Fprintf(stderr, "scru64 verify: invalid --window-limit: %v\n", *windowLimit)
+		return 2
+	}
+
+	input := stdin
+	if path := fs.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "scru64 verify: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+
+	counterSize := 24 - *nodeIdSize
+	groupOf := func(id scru64.Id) uint32 {
+		if *nodeIdSize == 0 {
+			return 0
+		}
+		return id.NodeCtr() >> counterSize
+	}
+
+	w := bufio.NewWriter(stdout)
+	defer w.Flush()
+	var lines, invalid, duplicates, unordered int
+	var newest uint64
+	var tracked int
+	var limitHit bool
+	last := map[uint32]verifyEntry{}
+	recent := map[uint64]map[scru64.Id]int{}
+
+	scanner := bufio.NewScanner(bufio.NewReaderSize(input, 1<<16))
+	for scanner.Scan() {
+		lines++
+		value := strings.TrimSpace(scanner.Text())
+		id, err := parseIdAs(value, *format)
+		if err != nil {
+			invalid++
+			fmt.Fprintf(w, "line %v: invalid: %v\n", lines, err)
+			continue
+		}
+
+		group := groupOf(id)
+		prev, hasPrev := last[group]
+		if hasPrev && id == prev.id {
+			duplicates++
+			fmt.Fprintf(w, "line %v: duplicate of line %v: %v\n", lines, prev.line, id)
+		} else if dup, ok := recent[id.Timestamp()][id]; ok {
+			duplicates++
+			fmt.Fprintf(w, "line %v: duplicate of line %v: %v\n", lines, dup, id)
+		} else if hasPrev && id < prev.id {
+			unordered++
+			fmt.Fprintf(w, "line %v: out of order: %v after %v on line %v\n",
+				lines, id, prev.id, prev.line)
+		}
+		if !hasPrev || id != prev.id {
+			last[group] = verifyEntry{id, lines}
+		}
+
+		if *window == 0 {
+			continue
+		}
+		timestamp := id.Timestamp()
+		if timestamp > newest {
+			newest = timestamp
+			for tick, bucket := range recent {
+				if tick+*window < newest {
+					tracked -= len(bucket)
+					delete(recent, tick)
+				}
+			}
+		}
+		if timestamp+*window >= newest {
+			if tracked >= *windowLimit {
+				limitHit = true
+				continue
+			}
+			bucket, ok := recent[timestamp]
+			if !ok {
+				bucket = map[scru64.Id]int{}
+				recent[timestamp] = bucket
+			}
+			if _, ok := bucket[id]; !ok {
+				bucket[id] = lines
+				tracked++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "scru64 verify: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stderr, "scru64 verify: %v lines, %v invalid, %v duplicates, %v out of order\n",
+		lines, invalid, duplicates, unordered)
+	if limitHit {
+		fmt.Fprintf(stderr,
+			"scru64 verify: --window-limit of %v IDs reached; duplicates may have been missed\n",
+			*windowLimit)
+	}
+	if invalid+duplicates+unordered > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Reports invalid lines, duplicates, and out-of-order IDs.
+func TestVerify(t *testing.T) {
+	id := func(timestamp uint64, nodeId uint32, counter uint32) string {
+		x, _ := scru64.FromParts(timestamp, nodeId<<16|counter)
+		return x.String()
+	}
+
+	input := strings.Join([]string{
+		id(1000, 1, 0),
+		id(1000, 1, 1),
+		id(1000, 2, 0), // ok only per nodeId
+		id(1000, 1, 2),
+		"invalid",
+		id(1000, 1, 2),
+		id(1001, 2, 1),
+		id(1000, 1, 1),
+		"",
+	}, "\n")
+
+	code, stdout, stderr := runCommand(t, input, "verify", "--window", "64")
+	for _, want := range []string{
+		"line 4: out of order: ",
+		"line 5: invalid: ",
+		"line 6: duplicate of line 4: ",
+		"line 8: duplicate of line 2: ",
+	} {
+		if code != 1 || !strings.Contains(stdout, want) {
+			t.Fatalf("%q not in %q", want, stdout)
+		}
+	}
+	if !strings.Contains(stderr, "8 lines, 1 invalid, 2 duplicates, 1 out of order") {
+		t.Fatalf("unexpected summary %q", stderr)
+	}
+
+	path := filepath.Join(t.TempDir(), "ids.txt")
+	os.WriteFile(path, []byte(input), 0o644)
+	code, stdout, _ = runCommand(t, "", "verify", "--node-id-size", "8", "--window", "64", path)
+	if code != 1 || strings.Contains(stdout, "out of order") ||
+		strings.Count(stdout, "duplicate") != 2 {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+
+	// duplicates beyond the window, which is disabled by default, are found only
+	// if adjacent
+	input = strings.Join([]string{
+		id(1000, 1, 0), id(1100, 1, 0), id(1000, 1, 0), id(1200, 1, 0), id(1200, 1, 0),
+	}, "\n")
+	code, stdout, _ = runCommand(t, input, "verify", "--node-id-size", "8")
+	if code != 1 || strings.Count(stdout, "duplicate") != 1 ||
+		!strings.Contains(stdout, "line 3: out of order") {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+	code, stdout, _ = runCommand(t, input, "verify", "--window", "1000")
+	if code != 1 || !strings.Contains(stdout, "line 3: duplicate of line 1") {
+		t.Fatalf("got %v, %q", code, stdout)
+	}
+
+	// window stops growing at the limit
+	input = strings.Join([]string{id(1000, 1, 0), id(1100, 1, 0), id(1000, 1, 1), id(1100, 1, 0)}, "\n")
+	code, stdout, stderr = runCommand(t, input, "verify", "--window", "1000", "--window-limit", "1")
+	if code != 1 || strings.Contains(stdout, "duplicate") ||
+		!strings.Contains(stderr, "--window-limit of 1 IDs reached") {
+		t.Fatalf("got %v, %q, %q", code, stdout, stderr)
+	}
+
+	code, _, _ = runCommand(t, id(1000, 1, 0)+"\n"+id(1000, 1, 1)+"\n", "verify")
+	if code != 0 {
+		t.Fatalf("got %v", code)
+	}
+}
@@ -0,0 +1,267 @@
+package scru64
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Represents a declarative configuration of a [Generator], typically loaded
+// from a JSON file:
+//
+//	{
+//	  "nodeSpec": "42/8",
+//	  "counterMode": "default:1",
+//	  "rollbackAllowance": "10s",
+//	  "sleepInterval": "64ms",
+//	  "stateFile": "/var/lib/myapp/scru64.state"
+//	}
+//
+// Only `nodeSpec` is required; the other fields fall back to the defaults of
+// [NewGenerator] when empty.
+type GeneratorConfig struct {
+	// The node spec string (see [NodeSpec]).
+	NodeSpec string `json:"nodeSpec"`
+
+	// The counter mode string (see [ParseCounterMode]). Defaults to the counter
+	// mode chosen by [NewGenerator].
+	CounterMode string `json:"counterMode,omitempty"`
+
+	// The amount of clock rollback that is considered significant, in the
+	// `time.ParseDuration` format (e.g., "10s"). Defaults to "10s".
+	RollbackAllowance string `json:"rollbackAllowance,omitempty"`
+
+	// The interval at which [Generator.GenerateOrSleep] retries after a
+	// significant clock rollback, in the `time.ParseDuration` format (e.g.,
+	// "64ms"). Defaults to "64ms".
+	SleepInterval string `json:"sleepInterval,omitempty"`
+
+	// The path of a file in which the generator saves its latest state.
+	//
+	// If set, the generator resumes from the `timestamp` tick next to the state
+	// saved in the file, if any, so that it keeps generating monotonically
+	// increasing IDs across restarts even if the clock has moved backwards in the
+	// meantime. The generator saves its state on a best-effort basis whenever the
+	// `timestamp` advances in the thread-safe generation methods, so the saved
+	// state may lag behind the IDs generated within the latest tick; skipping to
+	// the next tick covers them. Call [Generator.SaveState] at shutdown to save
+	// the final state and check for errors.
+	StateFile string `json:"stateFile,omitempty"`
+}
+
+// The error type returned when a [GeneratorConfig] field is invalid.
+type GeneratorConfigError struct {
+	// The JSON name of the invalid field.
+	Field string
+
+	// The underlying error.
+	Err error
+}
+
+// See error
+func (e *GeneratorConfigError) Error() string {
+	return fmt.Sprintf("scru64.GeneratorConfig: invalid %q: %v", e.Field, e.Err)
+}
+
+// Returns the underlying error.
+func (e *GeneratorConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Reads a [GeneratorConfig] from a JSON file.
+//
+// This function returns a non-nil error if the file cannot be read or if it
+// contains unknown fields or malformed JSON. The field values are not validated
+// until passed to [NewGeneratorFromConfig].
+func LoadGeneratorConfig(path string) (GeneratorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GeneratorConfig{}, fmt.Errorf(
+			"scru64.GeneratorConfig: could not read file: %w", err)
+	}
+	var config GeneratorConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return GeneratorConfig{}, fmt.Errorf(
+			"scru64.GeneratorConfig: could not decode %v: %w", path, err)
+	}
+	return config, nil
+}
+
+// Creates a new generator from a declarative configuration.
+//
+// Unlike [NewGeneratorParsing], this constructor returns a
+// [*GeneratorConfigError] naming the invalid field instead of panicking if any
+// field is invalid. It also returns a non-nil error if the state file exists
+// but is malformed or belongs to a different node.
+func NewGeneratorFromConfig(config GeneratorConfig) (*Generator, error) {
+	if config.NodeSpec == "" {
+		return nil, &GeneratorConfigError{"nodeSpec", errors.New("required")}
+	}
+	nodeSpec, err := ParseNodeSpec(config.NodeSpec)
+	if err != nil {
+		return nil, &GeneratorConfigError{"nodeSpec", err}
+	}
+
+	var g *Generator
+	if config.CounterMode == "" {
+		g = NewGenerator(nodeSpec)
+	} else {
+		counterMode, err := ParseCounterMode(config.CounterMode)
+		if err != nil {
+			return nil, &GeneratorConfigError{"counterMode", err}
+		}
+		g = NewGeneratorWithCounterMode(nodeSpec, counterMode)
+	}
+
+	if config.RollbackAllowance != "" {
+		d, err := time.ParseDuration(config.RollbackAllowance)
+		if err != nil {
+			return nil, &GeneratorConfigError{"rollbackAllowance", err}
+		} else if d < 0 || d.Milliseconds() >= (1<<48) {
+			return nil, &GeneratorConfigError{
+				"rollbackAllowance", fmt.Errorf("out of range: %v", d)}
+		}
+		g.rollbackAllowance = uint64(d.Milliseconds())
+	}
+
+	if config.SleepInterval != "" {
+		d, err := time.ParseDuration(config.SleepInterval)
+		if err != nil {
+			return nil, &GeneratorConfigError{"sleepInterval", err}
+		} else if d <= 0 {
+			return nil, &GeneratorConfigError{
+				"sleepInterval", fmt.Errorf("must be positive: %v", d)}
+		}
+		g.sleepInterval = d
+	}
+
+	if config.StateFile != "" {
+		nodePrev, err := loadGeneratorState(config.StateFile, nodeSpec)
+		if err != nil {
+			return nil, &GeneratorConfigError{"stateFile", err}
+		}
+		if nodePrev != 0 {
+			// skip the rest of the saved tick, which may include unsaved IDs, by
+			// pretending that its last counter value has been used
+			counterMask := uint32(1)<<g.counterSize - 1
+			nodePrev = mustFromParts(nodePrev.Timestamp(), nodePrev.NodeCtr()|counterMask)
+		}
+		if nodePrev > g.prev {
+			g.prev = nodePrev
+		}
+		g.state = &generatorState{path: config.StateFile, lastSaved: nodePrev}
+	}
+	return g, nil
+}
+
+// Reads the `nodePrev` saved in a state file, or returns zero if the file does
+// not exist.
+func loadGeneratorState(path string, nodeSpec NodeSpec) (Id, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	saved, err := ParseNodeSpec(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, err
+	} else if saved.NodeIdSize() != nodeSpec.NodeIdSize() ||
+		saved.NodeId() != nodeSpec.NodeId() {
+		return 0, fmt.Errorf(
+			"saved state %v does not belong to node %v/%v",
+			saved, nodeSpec.NodeId(), nodeSpec.NodeIdSize())
+	}
+	return saved.NodePrev(), nil
+}
+
+// Saves the latest state of a generator to a file.
+type generatorState struct {
+	path string
+
+	// the `timestamp` of the last state passed to autoSave, guarded by the
+	// generator's lock
+	lastQueued uint64
+
+	// serializes writes to the file
+	lock sync.Mutex
+
+	// the last state written to the file, guarded by `lock`
+	lastSaved Id
+}
+
+// Releases the generator's lock and then saves the state if the `timestamp`
+// has advanced since the last save, ignoring errors, so that the other callers
+// do not wait for the disk I/O.
+//
+// The caller must hold the lock.
+func (g *Generator) unlockAndAutoSave() {
+	s, prev := g.state, g.prev
+	if s == nil || prev.Timestamp() == s.lastQueued {
+		g.lock.Unlock()
+		return
+	}
+	s.lastQueued = prev.Timestamp()
+	g.lock.Unlock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if prev > s.lastSaved {
+		// do not overwrite a newer state saved by a concurrent caller
+		s.save(prev, g.NodeIdSize())
+	}
+}
+
+// Atomically replaces the state file with `prev`.
+//
+// The caller must hold `s.lock`.
+func (s *generatorState) save(prev Id, nodeIdSize uint8) error {
+	nodeSpec, _ := NewNodeSpecWithNodePrev(prev, nodeIdSize)
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(nodeSpec.String() + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err == nil {
+		s.lastSaved = prev
+	}
+	return err
+}
+
+// Saves the latest state of the generator to the state file configured by
+// [GeneratorConfig], so that a generator created from the same configuration
+// resumes from it.
+//
+// This method returns a non-nil error if the state file cannot be written. It
+// is a no-op if the generator was not created with a state file.
+func (g *Generator) SaveState() error {
+	g.verify()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.state == nil {
+		return nil
+	}
+	g.state.lock.Lock()
+	defer g.state.lock.Unlock()
+	if err := g.state.save(g.prev, g.NodeIdSize()); err != nil {
+		return fmt.Errorf("scru64.Generator: could not save state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package scru64
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Creates generators from declarative configurations.
+func TestNewGeneratorFromConfig(t *testing.T) {
+	g, err := NewGeneratorFromConfig(GeneratorConfig{NodeSpec: "42/8"})
+	assert(t, err == nil && g.NodeId() == 42 && g.NodeIdSize() == 8)
+	assert(t, g.rollbackAllowance == 10_000 && g.sleepInterval == 64*time.Millisecond)
+
+	g, err = NewGeneratorFromConfig(GeneratorConfig{
+		NodeSpec:          "0xb00/12",
+		CounterMode:       "zero",
+		RollbackAllowance: "1m",
+		SleepInterval:     "10ms",
+	})
+	assert(t, err == nil && g.NodeId() == 0xb00 && g.NodeIdSize() == 12)
+	assert(t, g.rollbackAllowance == 60_000 && g.sleepInterval == 10*time.Millisecond)
+	assert(t, g.GenerateOrSleep().NodeCtr()&0xfff == 0)
+}
+
+// Names the invalid field.
+func TestNewGeneratorFromConfigError(t *testing.T) {
+	var cases = []struct {
+		config GeneratorConfig
+		field  string
+	}{
+		{GeneratorConfig{}, "nodeSpec"},
+		{GeneratorConfig{NodeSpec: "42"}, "nodeSpec"},
+		{GeneratorConfig{NodeSpec: "42/8", CounterMode: "unknown"}, "counterMode"},
+		{GeneratorConfig{NodeSpec: "42/8", RollbackAllowance: "10"}, "rollbackAllowance"},
+		{GeneratorConfig{NodeSpec: "42/8", RollbackAllowance: "-1s"}, "rollbackAllowance"},
+		{GeneratorConfig{NodeSpec: "42/8", SleepInterval: "0s"}, "sleepInterval"},
+		{GeneratorConfig{NodeSpec: "42/8", StateFile: "/"}, "stateFile"},
+	}
+
+	for _, e := range cases {
+		g, err := NewGeneratorFromConfig(e.config)
+		var configErr *GeneratorConfigError
+		assert(t, g == nil && errors.As(err, &configErr) && configErr.Field == e.field)
+		assert(t, err != nil && strings.Contains(err.Error(), `"`+e.field+`"`))
+	}
+}
+
+// Resumes from and saves to state file.
+func TestGeneratorStateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scru64.state")
+	config := GeneratorConfig{NodeSpec: "42/8", StateFile: path}
+
+	g, err := NewGeneratorFromConfig(config)
+	assert(t, err == nil)
+	x := g.GenerateOrSleep()
+	data, err := os.ReadFile(path)
+	assert(t, err == nil && string(data) == x.String()+"/8\n")
+
+	// restart without saving the final state in the same tick
+	var prevs []Id
+	for i := 0; i < 5; i++ {
+		prevs = append(prevs, g.GenerateOrSleep())
+	}
+	g, err = NewGeneratorFromConfig(config)
+	assert(t, err == nil)
+	for i := 0; i < 5; i++ {
+		assert(t, g.GenerateOrSleep() > prevs[len(prevs)-1])
+	}
+
+	// resume from the tick next to future state as if clock went backwards
+	// across restart
+	future, _ := FromParts(x.Timestamp()+20, 42<<16|0x1234)
+	os.WriteFile(path, []byte(future.String()+"/8\n"), 0o644)
+	g, err = NewGeneratorFromConfig(config)
+	assert(t, err == nil)
+	y := g.GenerateOrSleep()
+	assert(t, y.Timestamp() == future.Timestamp()+1 && y.NodeCtr()>>16 == 42)
+	assert(t, g.SaveState() == nil)
+	data, _ = os.ReadFile(path)
+	assert(t, string(data) == y.String()+"/8\n")
+
+	// reject state of other node
+	_, err = NewGeneratorFromConfig(GeneratorConfig{NodeSpec: "43/8", StateFile: path})
+	assert(t, err != nil)
+	os.WriteFile(path, []byte("garbage"), 0o644)
+	_, err = NewGeneratorFromConfig(config)
+	assert(t, err != nil)
+
+	g, _ = NewGeneratorFromConfig(GeneratorConfig{NodeSpec: "42/8"})
+	assert(t, g.SaveState() == nil)
+}
+
+// Loads configuration from JSON file.
+func TestLoadGeneratorConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"nodeSpec": "42/8", "counterMode": "default:1", "sleepInterval": "1ms"}`), 0o644)
+	config, err := LoadGeneratorConfig(path)
+	assert(t, err == nil && config.NodeSpec == "42/8" && config.CounterMode == "default:1")
+	assert(t, config.SleepInterval == "1ms")
+
+	os.WriteFile(path, []byte(`{"nodeSpec": "42/8", "nodeSpecc": "42/8"}`), 0o644)
+	_, err = LoadGeneratorConfig(path)
+	assert(t, err != nil)
+
+	_, err = LoadGeneratorConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert(t, err != nil)
+}
@@ -0,0 +1,313 @@
+package scru64
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Represents the kind of anomaly reported by [ConflictDetector].
+type ConflictKind int
+
+const (
+	// The same ID was observed more than once.
+	ConflictDuplicate ConflictKind = iota + 1
+
+	// Two different sources generated IDs with the same `nodeId` within the same
+	// `timestamp` tick.
+	ConflictSharedNode
+
+	// A source generated an ID that is not greater than its preceding ID with the
+	// same `nodeId`, without the `timestamp` going back by more than the rollback
+	// allowance, after which [Generator.GenerateOrReset] legitimately restarts
+	// from a smaller ID.
+	ConflictRegression
+
+	// The counters observed within a `timestamp` tick of a `nodeId` form more
+	// than one run, which a single generator does not produce. This kind is
+	// reported only if `ConflictDetector.Complete` is set.
+	ConflictInterleaved
+)
+
+// Returns the name of the conflict kind.
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictDuplicate:
+		return "duplicate"
+	case ConflictSharedNode:
+		return "shared-node"
+	case ConflictRegression:
+		return "regression"
+	case ConflictInterleaved:
+		return "interleaved"
+	default:
+		return fmt.Sprintf("ConflictKind(%d)", int(k))
+	}
+}
+
+// Represents an anomaly suggesting that two generators share a `nodeId`.
+type Conflict struct {
+	// The kind of the anomaly.
+	Kind ConflictKind
+
+	// The ID that revealed the anomaly.
+	Id Id
+
+	// The `nodeId` shared by the suspected generators.
+	NodeId uint32
+
+	// The `timestamp` tick in which the anomaly was observed.
+	Timestamp uint64
+
+	// The sources involved, if known.
+	Sources []string
+}
+
+// Returns a human-readable description of the conflict.
+func (c Conflict) String() string {
+	return fmt.Sprintf("%v: id=%v nodeId=%v timestamp=%v sources=%q",
+		c.Kind, c.Id, c.NodeId, c.Timestamp, c.Sources)
+}
+
+// Detects generators sharing a `nodeId` from streams of observed IDs.
+//
+// The detector splits each observed ID into `nodeId` and `counter` according
+// to the `nodeIdSize` given at construction, and tracks the counters seen for
+// each `nodeId` within each `timestamp` tick. It reports the following
+// anomalies, none of which a single properly configured [Generator] produces:
+//
+//   - Duplicate IDs.
+//   - IDs with the same `nodeId` and `timestamp` from different sources.
+//   - Non-increasing IDs with the same `nodeId` from the same source.
+//   - More than one run of consecutive counters within a tick, if the detector
+//     observes every ID generated in the realm (see `Complete`).
+//
+// A source is an arbitrary label identifying a generator instance, such as a
+// host or pod name; IDs observed without a source are checked only for the
+// anomalies that do not involve sources. Sources must report their IDs in the
+// order of generation for regression checks to be meaningful.
+//
+// To bound memory usage, the detector keeps the state of only the most recent
+// `RetentionTicks` ticks relative to the largest `timestamp` observed, so it
+// cannot detect duplicates of IDs older than that, nor regressions of sources
+// that have been silent for longer than that. The largest `timestamp` is capped
+// at the current time plus the rollback allowance, so that IDs from a node
+// whose clock runs far ahead do not push the retention window past the IDs of
+// the other nodes.
+//
+// This structure must be instantiated by [NewConflictDetector]. It is safe for
+// concurrent use.
+type ConflictDetector struct {
+	// Whether the observed stream contains every ID generated in the realm,
+	// which enables the [ConflictInterleaved] check. Must be set before the first
+	// observation.
+	Complete bool
+
+	// The number of recent `timestamp` ticks to keep. Defaults to 64 (approx. 16
+	// seconds). Must be set before the first observation.
+	RetentionTicks uint64
+
+	// The amount of `unixTsMs` rollback that the observed generators consider
+	// significant (see [Generator.GenerateOrResetCore]), which also limits how
+	// far their timestamps can lead the wall clock. Defaults to `10_000`
+	// (milliseconds). Must be set before the first observation.
+	RollbackAllowance uint64
+
+	// Returns the current time. Defaults to `time.Now`; tests may replace it.
+	Now func() time.Time
+
+	nodeIdSize uint8
+	lock       sync.Mutex
+	newest     uint64
+	ticks      map[conflictTickKey]*conflictTickState
+	last       map[conflictSourceKey]Id
+	pending    []Conflict
+}
+
+// Identifies a `timestamp` tick of a `nodeId`.
+type conflictTickKey struct {
+	nodeId    uint32
+	timestamp uint64
+}
+
+// Identifies a `nodeId` of a source.
+type conflictSourceKey struct {
+	source string
+	nodeId uint32
+}
+
+// Tracks the counters and sources observed within a tick of a `nodeId`.
+type conflictTickState struct {
+	counters map[uint32]struct{}
+	sources  map[string]struct{}
+	reported bool
+}
+
+// Creates a new detector for the IDs generated by nodes of `nodeIdSize`.
+//
+// This function returns a non-nil error if the `nodeIdSize` is zero or greater
+// than 23.
+func NewConflictDetector(nodeIdSize uint8) (*ConflictDetector, error) {
+	if nodeIdSize == 0 || nodeIdSize >= nodeCtrSize {
+		return nil, fmt.Errorf(fmtNodeIdSizeError, nodeIdSize)
+	}
+	return &ConflictDetector{
+		RetentionTicks:    64,
+		RollbackAllowance: 10_000,
+		Now:               time.Now,
+		nodeIdSize:        nodeIdSize,
+		ticks:             map[conflictTickKey]*conflictTickState{},
+		last:              map[conflictSourceKey]Id{},
+	}, nil
+}
+
+// Records an ID of unknown source and returns the anomalies revealed by it.
+func (d *ConflictDetector) Observe(id Id) []Conflict {
+	return d.ObserveFrom("", id)
+}
+
+// Records an ID generated by `source` and returns the anomalies revealed by it.
+//
+// An empty `source` means that the source is unknown. The returned slice also
+// includes [ConflictInterleaved] anomalies of ticks that have fallen out of the
+// retention window.
+func (d *ConflictDetector) ObserveFrom(source string, id Id) []Conflict {
+	counterSize := nodeCtrSize - d.nodeIdSize
+	nodeId := id.NodeCtr() >> counterSize
+	counter := id.NodeCtr() & (1<<counterSize - 1)
+	timestamp := id.Timestamp()
+	newConflict := func(kind ConflictKind, sources []string) Conflict {
+		return Conflict{
+			Kind: kind, Id: id, NodeId: nodeId, Timestamp: timestamp, Sources: sources}
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	limit := (uint64(d.Now().UnixMilli()) + d.RollbackAllowance) >> 8
+	if timestamp > d.newest && d.newest < limit {
+		d.newest = min(timestamp, limit)
+		d.evict()
+	}
+	conflicts := d.pending
+	d.pending = nil
+
+	if source != "" {
+		key := conflictSourceKey{source: source, nodeId: nodeId}
+		if prev, ok := d.last[key]; !ok || id > prev {
+			d.last[key] = id
+		} else if timestamp+d.RollbackAllowance>>8 < prev.Timestamp() {
+			// a generator resets its state upon significant clock rollback
+			d.last[key] = id
+		} else {
+			conflicts = append(conflicts, newConflict(ConflictRegression, []string{source}))
+		}
+	}
+
+	if timestamp+d.RetentionTicks < d.newest {
+		// too old to track
+		return conflicts
+	}
+
+	key := conflictTickKey{nodeId: nodeId, timestamp: timestamp}
+	state, ok := d.ticks[key]
+	if !ok {
+		state = &conflictTickState{
+			counters: map[uint32]struct{}{},
+			sources:  map[string]struct{}{},
+		}
+		d.ticks[key] = state
+	}
+
+	if _, ok := state.counters[counter]; ok {
+		conflicts = append(conflicts, newConflict(ConflictDuplicate, sortedKeys(state.sources)))
+	}
+	state.counters[counter] = struct{}{}
+
+	if source != "" {
+		state.sources[source] = struct{}{}
+		if len(state.sources) > 1 && !state.reported {
+			state.reported = true
+			conflicts = append(conflicts, newConflict(ConflictSharedNode, sortedKeys(state.sources)))
+		}
+	}
+	return conflicts
+}
+
+// Checks and discards all the tracked ticks, returning the [ConflictInterleaved]
+// anomalies found in them along with any pending ones.
+//
+// Call this method after the last observation of a stream.
+func (d *ConflictDetector) Flush() []Conflict {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for key, state := range d.ticks {
+		d.checkRuns(key, state)
+		delete(d.ticks, key)
+	}
+	conflicts := d.pending
+	d.pending = nil
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Id < conflicts[j].Id })
+	return conflicts
+}
+
+// Discards the ticks and the last IDs of sources that have fallen out of the
+// retention window.
+//
+// The caller must hold the lock.
+func (d *ConflictDetector) evict() {
+	for key, state := range d.ticks {
+		if key.timestamp+d.RetentionTicks < d.newest {
+			d.checkRuns(key, state)
+			delete(d.ticks, key)
+		}
+	}
+	for key, id := range d.last {
+		if id.Timestamp()+d.RetentionTicks < d.newest {
+			delete(d.last, key)
+		}
+	}
+}
+
+// Queues a [ConflictInterleaved] anomaly if the counters of a tick form more
+// than one run.
+//
+// The caller must hold the lock.
+func (d *ConflictDetector) checkRuns(key conflictTickKey, state *conflictTickState) {
+	if !d.Complete || state.reported || len(state.counters) < 2 {
+		return
+	}
+
+	counters := make([]uint32, 0, len(state.counters))
+	for c := range state.counters {
+		counters = append(counters, c)
+	}
+	sort.Slice(counters, func(i, j int) bool { return counters[i] < counters[j] })
+	for i := 1; i < len(counters); i++ {
+		if counters[i] != counters[i-1]+1 {
+			counterSize := nodeCtrSize - d.nodeIdSize
+			id := mustFromParts(key.timestamp, key.nodeId<<counterSize|counters[i])
+			d.pending = append(d.pending, Conflict{
+				Kind:      ConflictInterleaved,
+				Id:        id,
+				NodeId:    key.nodeId,
+				Timestamp: key.timestamp,
+				Sources:   sortedKeys(state.sources),
+			})
+			return
+		}
+	}
+}
+
+// Returns the keys of a set in ascending order, or nil if the set is empty.
+func sortedKeys(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
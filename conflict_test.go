@@ -0,0 +1,124 @@
+package scru64
+
+import (
+	"testing"
+	"time"
+)
+
+// Collects the kinds of conflicts.
+func conflictKinds(conflicts []Conflict) []ConflictKind {
+	var kinds []ConflictKind
+	for _, e := range conflicts {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+// Reports nothing for streams of properly configured generators.
+func TestConflictDetectorNoFalsePositive(t *testing.T) {
+	d, err := NewConflictDetector(8)
+	assert(t, err == nil)
+	d.Complete = true
+
+	for _, e := range exampleNodeSpecs[:8] {
+		if e.nodeIdSize != 8 {
+			continue
+		}
+		nodeSpec, _ := NewNodeSpecWithNodeId(e.nodeId, e.nodeIdSize)
+		g := NewGenerator(nodeSpec)
+		var ts uint64 = 1_577_836_800_000
+		for i := 0; i < 10_000; i++ {
+			ts += uint64(i % 2)
+			x := g.GenerateOrResetCore(ts, 10_000)
+			assert(t, len(d.ObserveFrom(e.nodeSpec, x)) == 0)
+		}
+	}
+	assert(t, len(d.Flush()) == 0)
+}
+
+// Detects two generators sharing a `nodeId`.
+func TestConflictDetector(t *testing.T) {
+	nodeSpec, _ := NewNodeSpecWithNodeId(42, 8)
+	var ts uint64 = 1_577_836_800_000
+
+	// duplicates and shared node from tagged sources
+	d, _ := NewConflictDetector(8)
+	g1 := NewGeneratorWithCounterMode(nodeSpec, CounterModeFunc(
+		func(uint8, CounterModeRenewContext) uint32 { return 100 }))
+	g2 := NewGeneratorWithCounterMode(nodeSpec, CounterModeFunc(
+		func(uint8, CounterModeRenewContext) uint32 { return 200 }))
+	x := g1.GenerateOrResetCore(ts, 10_000)
+	assert(t, len(d.ObserveFrom("a", x)) == 0)
+	assert(t, len(d.ObserveFrom("a", x+1)) == 0)
+	cs := d.ObserveFrom("b", g2.GenerateOrResetCore(ts, 10_000))
+	assert(t, len(cs) == 1 && cs[0].Kind == ConflictSharedNode && cs[0].NodeId == 42)
+	assert(t, len(cs[0].Sources) == 2 && cs[0].Sources[0] == "a" && cs[0].Sources[1] == "b")
+	cs = d.ObserveFrom("c", x)
+	assert(t, len(cs) == 1 && cs[0].Kind == ConflictDuplicate)
+
+	// regression within a source
+	cs = d.ObserveFrom("a", x)
+	kinds := conflictKinds(cs)
+	assert(t, len(kinds) == 2 && kinds[0] == ConflictRegression && kinds[1] == ConflictDuplicate)
+
+	// untagged duplicates
+	d, _ = NewConflictDetector(8)
+	assert(t, len(d.Observe(x)) == 0)
+	cs = d.Observe(x)
+	assert(t, len(cs) == 1 && cs[0].Kind == ConflictDuplicate && cs[0].Sources == nil)
+
+	// interleaved runs in complete streams
+	d, _ = NewConflictDetector(8)
+	d.Complete = true
+	ts += 0x1000
+	for i := 0; i < 4; i++ {
+		assert(t, len(d.Observe(g1.GenerateOrResetCore(ts, 10_000))) == 0)
+		assert(t, len(d.Observe(g2.GenerateOrResetCore(ts, 10_000))) == 0)
+	}
+	cs = d.Flush()
+	assert(t, len(cs) == 1 && cs[0].Kind == ConflictInterleaved && cs[0].NodeId == 42)
+
+	// report interleaved runs when ticks fall out of retention window
+	for i := 0; i < 4; i++ {
+		d.Observe(g1.GenerateOrResetCore(ts+0x2000, 10_000))
+		d.Observe(g2.GenerateOrResetCore(ts+0x2000, 10_000))
+	}
+	late, _ := FromParts((ts+0x2000)>>8+100, 0)
+	cs = d.Observe(late)
+	assert(t, len(cs) == 1 && cs[0].Kind == ConflictInterleaved)
+	assert(t, cs[0].String() != "")
+
+	_, err := NewConflictDetector(0)
+	assert(t, err != nil)
+}
+
+// Keeps detecting after a future ID, accepts resets, and evicts idle sources.
+func TestConflictDetectorWindow(t *testing.T) {
+	nodeSpec, _ := NewNodeSpecWithNodeId(42, 8)
+	var ts uint64 = 1_577_836_800_000
+	d, _ := NewConflictDetector(8)
+	d.Now = func() time.Time { return time.UnixMilli(int64(ts)) }
+
+	// an ID from a node whose clock runs a day ahead does not evict others
+	g := NewGenerator(nodeSpec)
+	x := g.GenerateOrResetCore(ts, 10_000)
+	assert(t, len(d.ObserveFrom("a", x)) == 0)
+	future, _ := FromParts((ts+86_400_000)>>8, 0)
+	assert(t, len(d.ObserveFrom("z", future)) == 0)
+	cs := d.Observe(x)
+	assert(t, len(cs) == 1 && cs[0].Kind == ConflictDuplicate)
+
+	// counter reset by GenerateOrReset is not a regression
+	y := g.GenerateOrResetCore(ts-15_000, 10_000)
+	assert(t, y < x)
+	assert(t, len(d.ObserveFrom("a", y)) == 0)
+	cs = d.ObserveFrom("a", y)
+	assert(t, len(cs) > 0 && cs[0].Kind == ConflictRegression)
+
+	// last IDs of idle sources leave the retention window
+	assert(t, len(d.last) == 2)
+	ts += 60_000
+	d.Observe(g.GenerateOrResetCore(ts, 10_000))
+	_, ok := d.last[conflictSourceKey{source: "z", nodeId: 0}]
+	assert(t, len(d.last) == 1 && ok)
+}
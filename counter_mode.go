@@ -1,6 +1,15 @@
 package scru64
 
-import "math/rand"
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
 
 // An interface to customize the initial counter value for each new `timestamp`.
 //
@@ -55,3 +64,183 @@ func (c *defaultCounterMode) Renew(
 		return 0
 	}
 }
+
+// An adapter to allow the use of an ordinary function as a [CounterMode].
+type CounterModeFunc func(counterSize uint8, context CounterModeRenewContext) uint32
+
+// Calls `f(counterSize, context)`.
+func (f CounterModeFunc) Renew(
+	counterSize uint8, context CounterModeRenewContext) uint32 {
+	return f(counterSize, context)
+}
+
+// Creates a new instance of the "initialize a portion counter" mode that draws
+// random numbers from the cryptographically secure random number generator.
+//
+// This mode works the same as [NewDefaultCounterMode] except for the source of
+// randomness. It panics if the system random number generator fails.
+func NewCryptoCounterMode(overflowGuardSize uint8) CounterMode {
+	return &cryptoCounterMode{overflowGuardSize: overflowGuardSize}
+}
+
+// The "initialize a portion counter" strategy backed by `crypto/rand`.
+type cryptoCounterMode struct {
+	overflowGuardSize uint8
+}
+
+// Returns the next initial counter value of `counterSize` bits.
+func (c *cryptoCounterMode) Renew(
+	counterSize uint8, _ CounterModeRenewContext) uint32 {
+	if c.overflowGuardSize < counterSize {
+		var buf [4]byte
+		if _, err := crand.Read(buf[:]); err != nil {
+			panic(fmt.Errorf("scru64.CounterMode: could not read random bytes: %w", err))
+		}
+		return binary.BigEndian.Uint32(buf[:]) >> (32 + c.overflowGuardSize - counterSize)
+	} else {
+		return 0
+	}
+}
+
+// Creates a new instance of the "initialize a portion counter" mode that draws
+// random numbers from a pseudorandom number generator initialized with `seed`.
+//
+// This mode produces the same sequence of initial counter values for the same
+// seed, which is useful to reproduce generator behavior in tests. It should not
+// be used in production because generators sharing a seed are likely to start
+// from the same counter values.
+func NewSeededCounterMode(seed int64) CounterMode {
+	return &seededCounterMode{rng: rand.New(rand.NewSource(seed))}
+}
+
+// The "initialize a portion counter" strategy backed by a seeded `rand.Rand`.
+type seededCounterMode struct {
+	lock sync.Mutex
+	rng  *rand.Rand
+}
+
+// Returns the next initial counter value of `counterSize` bits.
+func (c *seededCounterMode) Renew(
+	counterSize uint8, _ CounterModeRenewContext) uint32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.rng.Uint32() >> (32 - counterSize)
+}
+
+// A function that creates a [CounterMode] from the argument part of a counter
+// mode string.
+//
+// The argument is the text following the first colon of a counter mode string
+// (e.g., "2" for "crypto:2"), or the empty string if the colon is absent.
+type CounterModeFactory func(arg string) (CounterMode, error)
+
+// The registry of named counter modes consulted by [ParseCounterMode].
+var counterModeRegistry = struct {
+	lock  sync.RWMutex
+	inner map[string]CounterModeFactory
+}{
+	inner: map[string]CounterModeFactory{
+		"default": func(arg string) (CounterMode, error) {
+			overflowGuardSize, err := parseOverflowGuardSize(arg)
+			if err != nil {
+				return nil, err
+			}
+			return NewDefaultCounterMode(overflowGuardSize), nil
+		},
+		"crypto": func(arg string) (CounterMode, error) {
+			overflowGuardSize, err := parseOverflowGuardSize(arg)
+			if err != nil {
+				return nil, err
+			}
+			return NewCryptoCounterMode(overflowGuardSize), nil
+		},
+		"zero": func(arg string) (CounterMode, error) {
+			if arg != "" {
+				return nil, fmt.Errorf("unexpected argument %q", arg)
+			}
+			return CounterModeFunc(
+				func(uint8, CounterModeRenewContext) uint32 { return 0 }), nil
+		},
+		"seeded": func(arg string) (CounterMode, error) {
+			seed, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid seed %q", arg)
+			}
+			return NewSeededCounterMode(seed), nil
+		},
+	},
+}
+
+// A regular expression representing the valid names of counter modes.
+var reCounterModeName = regexp.MustCompile(`^[a-z][0-9a-z_-]*$`)
+
+// Parses the optional decimal `overflowGuardSize` argument of a counter mode.
+func parseOverflowGuardSize(arg string) (uint8, error) {
+	if arg == "" {
+		return 0, nil
+	}
+	overflowGuardSize, err := strconv.ParseUint(arg, 10, 8)
+	if err != nil || overflowGuardSize >= uint64(nodeCtrSize) {
+		return 0, fmt.Errorf(
+			"`overflowGuardSize` (%q) must be a decimal integer from 0 to 23", arg)
+	}
+	return uint8(overflowGuardSize), nil
+}
+
+// Registers a named counter mode so that [ParseCounterMode] can create one from
+// a counter mode string.
+//
+// The name must consist of lowercase ASCII letters, digits, hyphens, and
+// underscores, starting with a letter. This function returns a non-nil error if
+// the name is invalid, if `factory` is nil, or if the name is already
+// registered, including the built-in "default", "crypto", "zero", and "seeded"
+// modes.
+func RegisterCounterMode(name string, factory CounterModeFactory) error {
+	if !reCounterModeName.MatchString(name) {
+		return fmt.Errorf("scru64.CounterMode: invalid counter mode name %q", name)
+	} else if factory == nil {
+		return fmt.Errorf("scru64.CounterMode: nil factory for counter mode %q", name)
+	}
+
+	counterModeRegistry.lock.Lock()
+	defer counterModeRegistry.lock.Unlock()
+	if _, ok := counterModeRegistry.inner[name]; ok {
+		return fmt.Errorf("scru64.CounterMode: counter mode %q already registered", name)
+	}
+	counterModeRegistry.inner[name] = factory
+	return nil
+}
+
+// Creates a [CounterMode] from a counter mode string.
+//
+// A counter mode string consists of a registered name, optionally followed by a
+// colon and an argument interpreted by the named mode. The built-in modes are:
+//
+//   - "default" or "default:<overflowGuardSize>": [NewDefaultCounterMode]
+//   - "crypto" or "crypto:<overflowGuardSize>": [NewCryptoCounterMode]
+//   - "zero": always resets the counter to zero
+//   - "seeded:<seed>": [NewSeededCounterMode]
+//
+// Other modes can be added by [RegisterCounterMode]. This function returns a
+// non-nil error if the name is not registered or if the named mode rejects the
+// argument.
+func ParseCounterMode(value string) (CounterMode, error) {
+	name, arg, _ := strings.Cut(value, ":")
+
+	counterModeRegistry.lock.RLock()
+	factory, ok := counterModeRegistry.inner[name]
+	counterModeRegistry.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("scru64.CounterMode: unknown counter mode %q", name)
+	}
+
+	c, err := factory(arg)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"scru64.CounterMode: could not create counter mode %q: %w", name, err)
+	} else if c == nil {
+		return nil, fmt.Errorf(
+			"scru64.CounterMode: factory for counter mode %q returned nil", name)
+	}
+	return c, nil
+}
@@ -45,3 +45,45 @@ func TestDefaultCounterMode(t *testing.T) {
 		}
 	}
 }
+
+// Parses built-in and registered counter mode strings.
+func TestParseCounterMode(t *testing.T) {
+	context := CounterModeRenewContext{Timestamp: 0x0123_4567_89ab, NodeId: 0}
+	for _, e := range []string{"default", "default:0", "default:1", "crypto", "crypto:2", "zero", "seeded:1234"} {
+		c, err := ParseCounterMode(e)
+		assert(t, c != nil && err == nil)
+		for counterSize := uint8(1); counterSize < nodeCtrSize; counterSize++ {
+			assert(t, c.Renew(counterSize, context) < (1<<counterSize))
+		}
+	}
+
+	c, _ := ParseCounterMode("zero")
+	assert(t, c.Renew(16, context) == 0)
+	c, _ = ParseCounterMode("crypto:16")
+	assert(t, c.Renew(16, context) == 0)
+
+	x, _ := ParseCounterMode("seeded:1234")
+	y, _ := ParseCounterMode("seeded:1234")
+	for i := 0; i < 16; i++ {
+		assert(t, x.Renew(16, context) == y.Renew(16, context))
+	}
+
+	for _, e := range []string{"", "unknown", "default:x", "default:24", "crypto:-1", "zero:1", "seeded", "seeded:x", "Default"} {
+		c, err := ParseCounterMode(e)
+		assert(t, c == nil && err != nil)
+	}
+
+	err := RegisterCounterMode("test-fixed", func(arg string) (CounterMode, error) {
+		return CounterModeFunc(func(counterSize uint8, _ CounterModeRenewContext) uint32 {
+			return 1
+		}), nil
+	})
+	assert(t, err == nil)
+	c, err = ParseCounterMode("test-fixed")
+	assert(t, err == nil && c.Renew(8, context) == 1)
+
+	assert(t, RegisterCounterMode("test-fixed", func(string) (CounterMode, error) { return nil, nil }) != nil)
+	assert(t, RegisterCounterMode("default", func(string) (CounterMode, error) { return nil, nil }) != nil)
+	assert(t, RegisterCounterMode("Bad Name", func(string) (CounterMode, error) { return nil, nil }) != nil)
+	assert(t, RegisterCounterMode("test-nil", nil) != nil)
+}
@@ -1,6 +1,7 @@
 package scru64
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -20,13 +21,15 @@ var ErrClockRollback = fmt.Errorf(
 //
 // The generator comes with several different methods that generate a SCRU64 ID:
 //
-//	| Flavor              | Timestamp | Thread- | On big clock rewind |
-//	| ------------------- | --------- | ------- | ------------------- |
-//	| Generate            | Now       | Safe    | Returns error       |
-//	| GenerateOrReset     | Now       | Safe    | Resets generator    |
-//	| GenerateOrSleep     | Now       | Safe    | Sleeps              |
-//	| GenerateOrAbortCore | Argument  | Unsafe  | Returns error       |
-//	| GenerateOrResetCore | Argument  | Unsafe  | Resets generator    |
+//	| Flavor                 | Timestamp | Thread- | On big clock rewind |
+//	| ---------------------- | --------- | ------- | ------------------- |
+//	| Generate               | Now       | Safe    | Returns error       |
+//	| GenerateBatch          | Now       | Safe    | Returns error       |
+//	| GenerateOrReset        | Now       | Safe    | Resets generator    |
+//	| GenerateOrSleep        | Now       | Safe    | Sleeps              |
+//	| GenerateOrSleepContext | Now       | Safe    | Sleeps or cancels   |
+//	| GenerateOrAbortCore    | Argument  | Unsafe  | Returns error       |
+//	| GenerateOrResetCore    | Argument  | Unsafe  | Resets generator    |
 //
 // All of these methods return a monotonically increasing ID by reusing the
 // previous `timestamp` even if the one provided is smaller than the immediately
@@ -46,10 +49,13 @@ var ErrClockRollback = fmt.Errorf(
 // The `Core` functions offer low-level thread-unsafe primitives to customize
 // the behavior.
 type Generator struct {
-	prev        Id
-	counterSize uint8
-	counterMode CounterMode
-	lock        sync.Mutex
+	prev              Id
+	counterSize       uint8
+	counterMode       CounterMode
+	rollbackAllowance uint64
+	sleepInterval     time.Duration
+	state             *generatorState
+	lock              sync.Mutex
 }
 
 // Heuristically ensures that the receiver is initialized by valid constructors,
@@ -92,9 +98,11 @@ func NewGeneratorWithCounterMode(
 		panic("constructor called with nil `counterMode`")
 	}
 	return &Generator{
-		prev:        nodeSpec.nodePrev,
-		counterSize: nodeCtrSize - nodeSpec.NodeIdSize(),
-		counterMode: counterMode,
+		prev:              nodeSpec.nodePrev,
+		counterSize:       nodeCtrSize - nodeSpec.NodeIdSize(),
+		counterMode:       counterMode,
+		rollbackAllowance: 10_000,
+		sleepInterval:     64 * time.Millisecond,
 	}
 }
 
@@ -136,8 +144,10 @@ func (g *Generator) renewNodeCtr(timestamp uint64) uint32 {
 // rollback.
 func (g *Generator) Generate() (Id, error) {
 	g.lock.Lock()
-	defer g.lock.Unlock()
-	return g.GenerateOrAbortCore(uint64(time.Now().UnixMilli()), 10_000)
+	value, err := g.GenerateOrAbortCore(
+		uint64(time.Now().UnixMilli()), g.rollbackAllowance)
+	g.unlockAndAutoSave()
+	return value, err
 }
 
 // Generates a new SCRU64 ID object from the current `timestamp`, or resets the
@@ -150,8 +160,10 @@ func (g *Generator) Generate() (Id, error) {
 // duplicate results.
 func (g *Generator) GenerateOrReset() Id {
 	g.lock.Lock()
-	defer g.lock.Unlock()
-	return g.GenerateOrResetCore(uint64(time.Now().UnixMilli()), 10_000)
+	value := g.GenerateOrResetCore(
+		uint64(time.Now().UnixMilli()), g.rollbackAllowance)
+	g.unlockAndAutoSave()
+	return value
 }
 
 // Returns a new SCRU64 ID object, or sleeps and waits for one if not
@@ -164,13 +176,66 @@ func (g *Generator) GenerateOrSleep() Id {
 		if err == nil {
 			return value
 		} else if err == ErrClockRollback {
-			time.Sleep(64 * time.Millisecond)
+			time.Sleep(g.sleepInterval)
 		} else {
 			panic("unreachable")
 		}
 	}
 }
 
+// Returns a new SCRU64 ID object, or sleeps and waits for one if not
+// immediately available, until the context is done.
+//
+// See the [Generator] type documentation for the description.
+//
+// This method returns the context's error if the context is done before an ID
+// becomes available.
+func (g *Generator) GenerateOrSleepContext(ctx context.Context) (Id, error) {
+	for {
+		value, err := g.Generate()
+		if err == nil {
+			return value, nil
+		} else if err != ErrClockRollback {
+			panic("unreachable")
+		}
+
+		timer := time.NewTimer(g.sleepInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Id(0), ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Generates `n` new SCRU64 ID objects at once from the current `timestamp`, or
+// returns an error upon significant timestamp rollback.
+//
+// The returned IDs are monotonically increasing, and no other call to the
+// generator interleaves with them. See the [Generator] type documentation for
+// the description.
+//
+// This method returns the [ErrClockRollback] error along with the IDs generated
+// before the clock rollback was detected. It panics if `n` is negative.
+func (g *Generator) GenerateBatch(n int) ([]Id, error) {
+	if n < 0 {
+		panic("`n` must not be negative")
+	}
+	g.lock.Lock()
+	defer g.unlockAndAutoSave()
+	values := make([]Id, 0, n)
+	unixTsMs := uint64(time.Now().UnixMilli())
+	for i := 0; i < n; i++ {
+		value, err := g.GenerateOrAbortCore(unixTsMs, g.rollbackAllowance)
+		if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
 // Generates a new SCRU64 ID object from a Unix timestamp in milliseconds, or
 // resets the generator upon significant timestamp rollback.
 //
@@ -1,6 +1,7 @@
 package scru64
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -145,3 +146,37 @@ func TestClockIntegration(t *testing.T) {
 		assert(t, x.Timestamp()-tsNow <= 1)
 	}
 }
+
+// Generates a batch of monotonically increasing IDs.
+func TestGenerateBatch(t *testing.T) {
+	g := NewGeneratorParsing("42/8")
+	xs, err := g.GenerateBatch(1000)
+	assert(t, err == nil && len(xs) == 1000)
+	for i := 1; i < len(xs); i++ {
+		assert(t, xs[i-1] < xs[i])
+	}
+	x, _ := g.Generate()
+	assert(t, xs[len(xs)-1] < x)
+
+	xs, err = g.GenerateBatch(0)
+	assert(t, err == nil && len(xs) == 0)
+
+	// returns partial results upon counter exhaustion beyond rollback allowance
+	g = NewGeneratorWithCounterMode(NodeSpec{nodeIdSize: 23}, NewCryptoCounterMode(0))
+	g.rollbackAllowance = 0
+	xs, err = g.GenerateBatch(1 << 10)
+	assert(t, err == ErrClockRollback && len(xs) < 1<<10)
+}
+
+// Stops sleeping when the context is canceled.
+func TestGenerateOrSleepContext(t *testing.T) {
+	g := NewGeneratorParsing("42/8")
+	x, err := g.GenerateOrSleepContext(context.Background())
+	assert(t, err == nil && x > 0)
+
+	g.prev, _ = FromParts(x.Timestamp()+1<<20, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = g.GenerateOrSleepContext(ctx)
+	assert(t, err == context.DeadlineExceeded)
+}
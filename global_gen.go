@@ -1,19 +1,32 @@
 package scru64
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 )
 
 // The gateway object that forwards supported method calls to the process-wide
 // global generator.
 //
 // By default, the global generator reads the node configuration from the
-// `SCRU64_NODE_SPEC` environment variable when a generator method is first
-// called, and it panics if it fails to do so. The node configuration is encoded
-// in a node spec string consisting of `nodeId` and `nodeIdSize` integers
-// separated by a slash (e.g., "42/8", "0xb00/12"; see [NodeSpec] for details).
+// environment when a generator method is first called, and it panics if it
+// fails to do so. The node configuration is typically encoded in a node spec
+// string consisting of `nodeId` and `nodeIdSize` integers separated by a slash
+// (e.g., "42/8", "0xb00/12"; see [NodeSpec] for details) and given by the
+// `SCRU64_NODE_SPEC` environment variable. See [DefaultNodeSpecSources] for the
+// other sources of the node configuration tried in order, which can be replaced
+// by `GlobalGenerator.SetNodeSpecSources`.
+//
+// The global generator also consults the following environment variables:
+//
+//   - `SCRU64_COUNTER_MODE`: a counter mode string (e.g., "default:1"; see
+//     [ParseCounterMode]).
+//   - `SCRU64_CONFIG_FILE`: the path of a JSON [GeneratorConfig] file, whose
+//     settings take precedence over the other environment variables.
+//
 // You can configure the global generator differently by calling
 // `GlobalGenerator.initialize` before the default initializer is triggered.
 var GlobalGenerator interface {
@@ -28,12 +41,62 @@ var GlobalGenerator interface {
 	// `false` if it preserves the existing configuration.
 	Initialize(nodeSpec NodeSpec) bool
 
+	// Initializes the global generator, if not initialized, with the node spec
+	// and counter mode passed.
+	//
+	// This method behaves like `Initialize` except that it configures the global
+	// generator with `NewGeneratorWithCounterMode`.
+	InitializeWithCounterMode(nodeSpec NodeSpec, counterMode CounterMode) bool
+
+	// Initializes the global generator, if not initialized, with the declarative
+	// configuration passed.
+	//
+	// This method behaves like `Initialize` except that it configures the global
+	// generator with `NewGeneratorFromConfig` and returns the error that
+	// `NewGeneratorFromConfig` returns, if any, in which case the global generator
+	// remains uninitialized.
+	InitializeWithConfig(config GeneratorConfig) (bool, error)
+
+	// Sets the chain of node spec sources that the default initializer tries in
+	// order (see [ResolveNodeSpec]), if the global generator is not initialized.
+	//
+	// This method returns `true` if it replaces the default chain returned by
+	// [DefaultNodeSpecSources] or `false` if the global generator has already
+	// been initialized.
+	SetNodeSpecSources(sources ...NodeSpecSource) bool
+
+	// Initializes the global generator, if not initialized, with the default
+	// initializer and returns the name of the source from which the global
+	// generator obtained its node configuration, for diagnostics.
+	//
+	// The name is one of the [NodeSpecSource] names, "SCRU64_CONFIG_FILE" if the
+	// config file specifies the node spec, "Initialize" if the global generator
+	// is configured by one of the `Initialize` methods, "Swap" if it is
+	// installed by `Swap`, or an empty string if the default initializer fails.
+	NodeSpecSource() string
+
 	// Calls `Generator.Generate` of the global generator.
 	Generate() (Id, error)
 
+	// Calls `Generator.GenerateBatch` of the global generator.
+	GenerateBatch(n int) ([]Id, error)
+
+	// Calls `Generator.GenerateOrReset` of the global generator.
+	GenerateOrReset() Id
+
 	// Calls `Generator.GenerateOrSleep` of the global generator.
 	GenerateOrSleep() Id
 
+	// Calls `Generator.GenerateOrSleepContext` of the global generator.
+	GenerateOrSleepContext(ctx context.Context) (Id, error)
+
+	// Returns the underlying generator of the global generator.
+	//
+	// This method is useful to pass the global generator to functions that accept
+	// a `*Generator`. Note that `Swap` replaces the global generator but not the
+	// references previously returned by this method.
+	Generator() *Generator
+
 	// Calls `Generator.NodeId` of the global generator.
 	NodeId() uint32
 
@@ -42,43 +105,241 @@ var GlobalGenerator interface {
 
 	// Calls `Generator.NodeSpec` of the global generator.
 	NodeSpec() NodeSpec
+
+	// Initializes the global generator, if not initialized, with the default
+	// initializer and returns the error that the default initializer
+	// encountered, if any.
+	//
+	// This method returns nil if the global generator is properly configured,
+	// either by the default initializer or by `Initialize`. If the default
+	// initializer fails, the global generator remains uninitialized: the methods
+	// that generate IDs or report the node configuration panic, the default
+	// initializer runs again at the next method call, and the `Initialize`
+	// methods can still configure the global generator.
+	Err() error
+
+	// Replaces the global generator with `generator` and returns the previous
+	// one, or nil if the global generator was not initialized.
+	//
+	// Passing nil resets the global generator to the uninitialized state, in
+	// which the default initializer runs again at the next method call. This
+	// method is primarily intended for tests that need to install a generator
+	// temporarily and restore the previous one afterwards (see package
+	// [github.com/scru64/go-scru64/scru64test]). Because the global generator is
+	// shared by the entire process, swapping it while other goroutines generate
+	// IDs breaks the monotonicity of their results.
+	Swap(generator *Generator) *Generator
 } = &globalGeneratorInner{}
 
 // The lazy initialization holder type of the global generator.
 type globalGeneratorInner struct {
-	once  sync.Once
-	inner *Generator
+	// serializes initialization and swaps
+	lock sync.Mutex
+
+	// the fast path to the initialized generator
+	inner atomic.Pointer[Generator]
+
+	// whether the default initializer, `Initialize`, or `Swap` has installed a
+	// generator; guarded by `lock`
+	done bool
+
+	// the node spec sources tried by the default initializer, or nil for the
+	// default chain; guarded by `lock`
+	sources []NodeSpecSource
+
+	// the name of the source of the node configuration; guarded by `lock`
+	source string
+}
+
+// Returns the global generator, initializing it with the default initializer if
+// not initialized, or the error that the default initializer encountered.
+func (g *globalGeneratorInner) tryGet() (*Generator, error) {
+	if inner := g.inner.Load(); inner != nil {
+		return inner, nil
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.done {
+		sources := g.sources
+		if sources == nil {
+			sources = DefaultNodeSpecSources()
+		}
+		inner, source, err := newGeneratorFromEnv(sources)
+		if err != nil {
+			// stay uninitialized so that a later call or `Initialize` may succeed
+			return nil, err
+		}
+		g.inner.Store(inner)
+		g.done = true
+		g.source = source
+	}
+	return g.inner.Load(), nil
 }
 
 func (g *globalGeneratorInner) get() *Generator {
-	g.once.Do(func() {
-		nodeSpec, err := ParseNodeSpec(os.Getenv("SCRU64_NODE_SPEC"))
+	inner, err := g.tryGet()
+	if err != nil {
+		panic(err)
+	}
+	return inner
+}
+
+func (g *globalGeneratorInner) Err() error {
+	_, err := g.tryGet()
+	return err
+}
+
+func (g *globalGeneratorInner) SetNodeSpecSources(sources ...NodeSpecSource) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.done {
+		return false
+	}
+	g.sources = append([]NodeSpecSource{}, sources...)
+	return true
+}
+
+func (g *globalGeneratorInner) NodeSpecSource() string {
+	g.tryGet()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.source
+}
+
+// Initializes the global generator, if not initialized, with the default
+// initializer that reads the environment variables, and returns the error that
+// the default initializer encountered, if any.
+//
+// Call this function at the beginning of `main` to fail fast with a readable
+// error rather than panicking at the first call to [New] or [NewString]. This
+// function returns nil if the global generator has already been configured by
+// `GlobalGenerator.Initialize()`.
+func InitializeFromEnv() error {
+	return GlobalGenerator.Err()
+}
+
+// Creates a generator configured by the `SCRU64_CONFIG_FILE` and
+// `SCRU64_COUNTER_MODE` environment variables and the node spec obtained from
+// `sources`, returning the name of the source of the node spec as well.
+//
+// The node spec and counter mode in the config file, if any, take precedence
+// over the node spec sources and the environment variable.
+func newGeneratorFromEnv(sources []NodeSpecSource) (*Generator, string, error) {
+	var config GeneratorConfig
+	if path, ok := os.LookupEnv("SCRU64_CONFIG_FILE"); ok {
+		var err error
+		config, err = LoadGeneratorConfig(path)
+		if err != nil {
+			return nil, "", fmt.Errorf(
+				"scru64: could not read config from SCRU64_CONFIG_FILE env var: %w", err)
+		}
+	}
+
+	source := "SCRU64_CONFIG_FILE"
+	if config.NodeSpec == "" {
+		nodeSpec, name, err := ResolveNodeSpec(sources)
 		if err != nil {
-			panic(fmt.Errorf(
-				"scru64: could not read config from SCRU64_NODE_SPEC env var: %w", err))
+			return nil, "", err
+		}
+		config.NodeSpec = nodeSpec.String()
+		source = name
+	}
+
+	if config.CounterMode == "" {
+		if value, ok := os.LookupEnv("SCRU64_COUNTER_MODE"); ok {
+			if _, err := ParseCounterMode(value); err != nil {
+				return nil, "", fmt.Errorf(
+					"scru64: could not read config from SCRU64_COUNTER_MODE env var: %w", err)
+			}
+			config.CounterMode = value
 		}
-		g.inner = NewGenerator(nodeSpec)
-	})
-	return g.inner
+	}
+
+	g, err := NewGeneratorFromConfig(config)
+	if err != nil {
+		return nil, "", fmt.Errorf("scru64: could not configure global generator: %w", err)
+	}
+	return g, source, nil
 }
 
 func (g *globalGeneratorInner) Initialize(nodeSpec NodeSpec) bool {
-	initialized := false
-	g.once.Do(func() {
-		g.inner = NewGenerator(nodeSpec)
-		initialized = true
-	})
-	return initialized
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.done {
+		return false
+	}
+	g.inner.Store(NewGenerator(nodeSpec))
+	g.done = true
+	g.source = "Initialize"
+	return true
+}
+
+func (g *globalGeneratorInner) InitializeWithCounterMode(
+	nodeSpec NodeSpec, counterMode CounterMode,
+) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.done {
+		return false
+	}
+	g.inner.Store(NewGeneratorWithCounterMode(nodeSpec, counterMode))
+	g.done = true
+	g.source = "Initialize"
+	return true
+}
+
+func (g *globalGeneratorInner) InitializeWithConfig(config GeneratorConfig) (bool, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.done {
+		return false, nil
+	}
+	inner, err := NewGeneratorFromConfig(config)
+	if err != nil {
+		return false, err
+	}
+	g.inner.Store(inner)
+	g.done = true
+	g.source = "Initialize"
+	return true, nil
+}
+
+func (g *globalGeneratorInner) Swap(generator *Generator) *Generator {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.done = generator != nil
+	g.source = ""
+	if generator != nil {
+		g.source = "Swap"
+	}
+	return g.inner.Swap(generator)
 }
 
 func (g *globalGeneratorInner) Generate() (Id, error) {
 	return g.get().Generate()
 }
 
+func (g *globalGeneratorInner) GenerateBatch(n int) ([]Id, error) {
+	return g.get().GenerateBatch(n)
+}
+
+func (g *globalGeneratorInner) GenerateOrReset() Id {
+	return g.get().GenerateOrReset()
+}
+
 func (g *globalGeneratorInner) GenerateOrSleep() Id {
 	return g.get().GenerateOrSleep()
 }
 
+func (g *globalGeneratorInner) GenerateOrSleepContext(ctx context.Context) (Id, error) {
+	return g.get().GenerateOrSleepContext(ctx)
+}
+
+func (g *globalGeneratorInner) Generator() *Generator {
+	return g.get()
+}
+
 func (g *globalGeneratorInner) NodeId() uint32 {
 	return g.get().NodeId()
 }
@@ -1,12 +1,19 @@
 package scru64
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Reads configuration from environment var.
 func TestDefaultInitializer(t *testing.T) {
 	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	prev := GlobalGenerator.Swap(nil)
+	t.Cleanup(func() { GlobalGenerator.Swap(prev) })
 
 	assert(t, GlobalGenerator.NodeId() == 42)
 	assert(t, GlobalGenerator.NodeIdSize() == 8)
@@ -23,3 +30,131 @@ func TestNewString(t *testing.T) {
 		prev = curr
 	}
 }
+
+// Reads configuration from config file specified by environment var.
+func TestNewGeneratorFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"nodeSpec": "0xb00/12", "sleepInterval": "1ms"}`), 0o644)
+	t.Setenv("SCRU64_CONFIG_FILE", path)
+	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	t.Setenv("SCRU64_COUNTER_MODE", "zero")
+
+	g, source, err := newGeneratorFromEnv(DefaultNodeSpecSources())
+	assert(t, err == nil && g.NodeId() == 0xb00 && g.NodeIdSize() == 12)
+	assert(t, source == "SCRU64_CONFIG_FILE")
+	assert(t, g.sleepInterval == time.Millisecond)
+	assert(t, g.GenerateOrSleep().NodeCtr()&0xfff == 0)
+
+	os.WriteFile(path, []byte(`{"sleepInterval": "1ms"}`), 0o644)
+	g, source, err = newGeneratorFromEnv(DefaultNodeSpecSources())
+	assert(t, err == nil && g.NodeId() == 42 && g.NodeIdSize() == 8)
+	assert(t, source == "SCRU64_NODE_SPEC env var")
+
+	os.WriteFile(path, []byte(`{"sleepInterval": "x"}`), 0o644)
+	_, _, err = newGeneratorFromEnv(DefaultNodeSpecSources())
+	assert(t, err != nil)
+
+	os.Unsetenv("SCRU64_CONFIG_FILE")
+	t.Setenv("SCRU64_COUNTER_MODE", "unknown")
+	_, _, err = newGeneratorFromEnv(DefaultNodeSpecSources())
+	assert(t, err != nil)
+}
+
+// Reports initialization errors instead of panicking.
+func TestGlobalGeneratorErr(t *testing.T) {
+	t.Setenv("SCRU64_NODE_SPEC", "42/800")
+	g := &globalGeneratorInner{}
+	err := g.Err()
+	assert(t, err != nil && strings.Contains(err.Error(), "SCRU64_NODE_SPEC"))
+	assert(t, g.Err().Error() == err.Error())
+
+	func() {
+		defer func() {
+			assert(t, recover() != nil)
+		}()
+		g.NodeId()
+	}()
+
+	// recover from failed default initialization
+	assert(t, g.Initialize(NodeSpec{nodeIdSize: 8}))
+	assert(t, g.Err() == nil && g.NodeSpecSource() == "Initialize")
+	g = &globalGeneratorInner{}
+	assert(t, g.Err() != nil)
+	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	assert(t, g.Err() == nil && g.NodeId() == 42)
+}
+
+// Generates IDs without panicking once configured.
+func TestTryNew(t *testing.T) {
+	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	assert(t, InitializeFromEnv() == nil)
+
+	x, err := TryNew()
+	assert(t, err == nil && x.NodeCtr()>>16 == 42)
+	s, err := TryNewString()
+	assert(t, err == nil && len(s) == 12 && s > x.String())
+}
+
+// Swaps the global generator and its initialization state.
+func TestGlobalGeneratorSwap(t *testing.T) {
+	g := &globalGeneratorInner{}
+	assert(t, g.Swap(NewGeneratorParsing("1/1")) == nil)
+	assert(t, g.NodeId() == 1 && g.Err() == nil)
+	assert(t, !g.Initialize(NodeSpec{nodeIdSize: 8}))
+
+	prev := g.Swap(nil)
+	assert(t, prev != nil && prev.NodeId() == 1)
+	t.Setenv("SCRU64_NODE_SPEC", "42/800")
+	assert(t, g.Err() != nil)
+
+	g.Swap(nil)
+	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	assert(t, g.Err() == nil && g.NodeId() == 42)
+}
+
+// Forwards the extended methods to the underlying generator.
+func TestGlobalGeneratorParity(t *testing.T) {
+	g := &globalGeneratorInner{}
+	assert(t, g.InitializeWithCounterMode(
+		NodeSpec{nodeIdSize: 8}, CounterModeFunc(func(uint8, CounterModeRenewContext) uint32 { return 0 })))
+	assert(t, !g.InitializeWithCounterMode(NodeSpec{nodeIdSize: 8}, nil))
+	assert(t, g.Generator() != nil && g.Generator().NodeIdSize() == 8)
+
+	x := g.GenerateOrReset()
+	assert(t, x.NodeCtr() == 0)
+	xs, err := g.GenerateBatch(3)
+	assert(t, err == nil && len(xs) == 3 && x < xs[0] && xs[0] < xs[1] && xs[1] < xs[2])
+	y, err := g.GenerateOrSleepContext(context.Background())
+	assert(t, err == nil && xs[2] < y)
+
+	g = &globalGeneratorInner{}
+	ok, err := g.InitializeWithConfig(GeneratorConfig{NodeSpec: "42"})
+	assert(t, !ok && err != nil)
+	ok, err = g.InitializeWithConfig(GeneratorConfig{NodeSpec: "42/8", CounterMode: "zero"})
+	assert(t, ok && err == nil && g.NodeId() == 42)
+	ok, err = g.InitializeWithConfig(GeneratorConfig{NodeSpec: "43/8"})
+	assert(t, !ok && err == nil && g.NodeId() == 42)
+}
+
+// Resolves the node spec through the configured sources.
+func TestGlobalGeneratorNodeSpecSources(t *testing.T) {
+	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	g := &globalGeneratorInner{}
+	assert(t, g.SetNodeSpecSources(
+		NewNodeSpecSource("test", func() (NodeSpec, error) { return NodeSpec{nodeIdSize: 12}, nil })))
+	assert(t, g.NodeIdSize() == 12 && g.NodeSpecSource() == "test")
+	assert(t, !g.SetNodeSpecSources())
+
+	g = &globalGeneratorInner{}
+	assert(t, g.NodeId() == 42 && g.NodeSpecSource() == "SCRU64_NODE_SPEC env var")
+	g.Swap(NewGeneratorParsing("1/1"))
+	assert(t, g.NodeSpecSource() == "Swap")
+
+	g = &globalGeneratorInner{}
+	g.Initialize(NodeSpec{nodeIdSize: 8})
+	assert(t, g.NodeSpecSource() == "Initialize")
+
+	g = &globalGeneratorInner{}
+	g.SetNodeSpecSources()
+	assert(t, g.Err() != nil && g.NodeSpecSource() == "")
+}
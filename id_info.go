@@ -0,0 +1,83 @@
+package scru64
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Represents the decoded fields of a SCRU64 ID, produced by [Id.Inspect] and
+// [NodeRegistry.Inspect].
+type IdInfo struct {
+	// The inspected ID.
+	Id Id `json:"id"`
+
+	// The `timestamp` field value, i.e., the number of 256-millisecond ticks
+	// since the Unix epoch.
+	Timestamp uint64 `json:"timestamp"`
+
+	// The start of the `timestamp` tick.
+	Time time.Time `json:"time"`
+
+	// The combined `nodeId` and `counter` field value.
+	NodeCtr uint32 `json:"nodeCtr"`
+
+	// The `nodeIdSize` assumed to split `NodeCtr`, or zero if unknown.
+	NodeIdSize uint8 `json:"nodeIdSize,omitempty"`
+
+	// The `nodeId` field value; valid only if `NodeIdSize` is nonzero, and
+	// omitted from the JSON representation otherwise.
+	NodeId uint32 `json:"nodeId"`
+
+	// The `counter` field value; valid only if `NodeIdSize` is nonzero, and
+	// omitted from the JSON representation otherwise.
+	Counter uint32 `json:"counter"`
+
+	// The registered node that could have generated the ID, if known.
+	Node *NodeRegistryEntry `json:"node,omitempty"`
+}
+
+// See encoding/json.Marshaler
+func (info IdInfo) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Id         Id                 `json:"id"`
+		Timestamp  uint64             `json:"timestamp"`
+		Time       time.Time          `json:"time"`
+		NodeCtr    uint32             `json:"nodeCtr"`
+		NodeIdSize uint8              `json:"nodeIdSize,omitempty"`
+		NodeId     *uint32            `json:"nodeId,omitempty"`
+		Counter    *uint32            `json:"counter,omitempty"`
+		Node       *NodeRegistryEntry `json:"node,omitempty"`
+	}{info.Id, info.Timestamp, info.Time, info.NodeCtr, info.NodeIdSize, nil, nil, info.Node}
+	if info.NodeIdSize > 0 {
+		v.NodeId, v.Counter = &info.NodeId, &info.Counter
+	}
+	return json.Marshal(v)
+}
+
+// Returns the start of the `timestamp` tick as a `time.Time` value.
+func (n Id) Time() time.Time {
+	return time.UnixMilli(int64(n.Timestamp() << 8))
+}
+
+// Decodes the fields of the ID, splitting the `nodeCtr` field into `nodeId` and
+// `counter` if `nodeIdSize` is nonzero.
+//
+// This method panics if `nodeIdSize` is greater than 23.
+func (n Id) Inspect(nodeIdSize uint8) IdInfo {
+	if nodeIdSize >= nodeCtrSize {
+		panic("`nodeIdSize` out of range")
+	}
+	info := IdInfo{
+		Id:         n,
+		Timestamp:  n.Timestamp(),
+		Time:       n.Time(),
+		NodeCtr:    n.NodeCtr(),
+		NodeIdSize: nodeIdSize,
+	}
+	if nodeIdSize > 0 {
+		counterSize := nodeCtrSize - nodeIdSize
+		info.NodeId = info.NodeCtr >> counterSize
+		info.Counter = info.NodeCtr & (1<<counterSize - 1)
+	}
+	return info
+}
@@ -0,0 +1,42 @@
+package scru64
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Decodes fields with and without `nodeIdSize`.
+func TestInspect(t *testing.T) {
+	for _, e := range exampleIds {
+		x := Id(e.num)
+		info := x.Inspect(0)
+		assert(t, info.Id == x && info.Timestamp == e.timestamp && info.NodeCtr == e.nodeCtr)
+		assert(t, info.Time.Equal(time.UnixMilli(int64(e.timestamp<<8))))
+		assert(t, info.NodeIdSize == 0 && info.NodeId == 0 && info.Counter == 0)
+		assert(t, info.Node == nil)
+
+		for nodeIdSize := uint8(1); nodeIdSize < nodeCtrSize; nodeIdSize++ {
+			info = x.Inspect(nodeIdSize)
+			counterSize := nodeCtrSize - nodeIdSize
+			assert(t, info.NodeIdSize == nodeIdSize)
+			assert(t, info.NodeId<<counterSize|info.Counter == e.nodeCtr)
+			assert(t, info.Counter < 1<<counterSize)
+		}
+	}
+}
+
+// Includes zero `nodeId` and `counter` in JSON only if `nodeIdSize` is known.
+func TestIdInfoMarshalJSON(t *testing.T) {
+	x, _ := FromParts(0x1234567890, 5)
+	data, err := json.Marshal(x.Inspect(8))
+	assert(t, err == nil && strings.Contains(string(data), `"nodeIdSize":8,"nodeId":0,"counter":5`))
+
+	var info IdInfo
+	assert(t, json.Unmarshal(data, &info) == nil && info.NodeId == 0 && info.Counter == 5)
+
+	data, err = json.Marshal(x.Inspect(0))
+	assert(t, err == nil && !strings.Contains(string(data), "nodeId"))
+	assert(t, !strings.Contains(string(data), "counter"))
+}
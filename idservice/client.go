@@ -0,0 +1,323 @@
+package idservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// The default maximum amount of time that a single request of
+// [RemoteGenerator] to the service may take.
+const DefaultTimeout = 10 * time.Second
+
+// The interval at which [RemoteGenerator.GenerateOrSleepContext] retries failed
+// requests.
+const retryInterval = 100 * time.Millisecond
+
+// The generation methods shared by [scru64.Generator] and [RemoteGenerator].
+//
+// Code that depends on this interface instead of a concrete type can switch
+// between a local generator and the ID service without changes.
+type IdGenerator interface {
+	// Returns the `nodeId` of the generator.
+	NodeId() uint32
+
+	// Returns the size in bits of the `nodeId` of the generator.
+	NodeIdSize() uint8
+
+	// Returns the node configuration of the generator.
+	NodeSpec() scru64.NodeSpec
+
+	// Returns a new SCRU64 ID object, or an error if the generator cannot
+	// return one immediately.
+	Generate() (scru64.Id, error)
+
+	// Returns `n` new SCRU64 ID objects, or an error along with the IDs
+	// generated before the failure.
+	GenerateBatch(n int) ([]scru64.Id, error)
+
+	// Returns a new SCRU64 ID object, waiting until the generator can return
+	// one or the context is done.
+	GenerateOrSleepContext(ctx context.Context) (scru64.Id, error)
+}
+
+var (
+	_ IdGenerator = (*scru64.Generator)(nil)
+	_ IdGenerator = (*RemoteGenerator)(nil)
+)
+
+// A client of the ID service [Server].
+type Client struct {
+	// The base URL of the ID service (e.g., "http://idservice:8080").
+	BaseURL string
+
+	// The HTTP client used to send requests. Defaults to `http.DefaultClient`.
+	HTTPClient *http.Client
+}
+
+// Creates a new client of the ID service at `baseURL`.
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Sends a GET request for a JSON response to the endpoint and decodes it.
+func (c *Client) get(ctx context.Context, endpoint string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e errorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("%v: %v", resp.Status, e.Error)
+	} else if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("invalid response body: %w", err)
+	}
+	return nil
+}
+
+// Returns the node configuration of the service's generator.
+func (c *Client) NodeSpec(ctx context.Context) (scru64.NodeSpec, error) {
+	var resp healthResponse
+	if err := c.get(ctx, "/healthz", &resp); err != nil {
+		return scru64.NodeSpec{}, fmt.Errorf("idservice.Client: could not get node spec: %w", err)
+	}
+	return resp.NodeSpec, nil
+}
+
+// Fetches `n` IDs from the service in a single request.
+func (c *Client) GenerateBatch(ctx context.Context, n int) ([]scru64.Id, error) {
+	var resp idsResponse
+	if err := c.get(ctx, fmt.Sprintf("/ids?n=%d", n), &resp); err != nil {
+		return nil, fmt.Errorf("idservice.Client: could not generate IDs: %w", err)
+	} else if len(resp.Ids) != n {
+		return nil, fmt.Errorf(
+			"idservice.Client: could not generate IDs: got %v IDs (expected %v)", len(resp.Ids), n)
+	}
+	return resp.Ids, nil
+}
+
+// Creates a [RemoteGenerator] that fetches batches of `batchSize` IDs from the
+// service.
+//
+// This method fetches the node configuration and the first batch before
+// returning, so it returns a non-nil error if the service is unreachable. It
+// also returns a non-nil error if `batchSize` is not positive.
+func (c *Client) NewRemoteGenerator(ctx context.Context, batchSize int) (*RemoteGenerator, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("idservice.Client: `batchSize` (%v) must be positive", batchSize)
+	}
+	nodeSpec, err := c.NodeSpec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := c.GenerateBatch(ctx, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteGenerator{
+		Timeout:   DefaultTimeout,
+		client:    c,
+		batchSize: batchSize,
+		nodeSpec:  nodeSpec,
+		buffer:    ids,
+	}, nil
+}
+
+// A generator that hands out IDs fetched in batches from the ID service.
+//
+// A remote generator offers the thread-safe generation methods of
+// [scru64.Generator] and implements [IdGenerator] along with it, so that it can
+// replace a local generator without a round trip per ID. It keeps a buffer of
+// IDs and starts fetching the next batch in the background when a quarter of a
+// batch is left. The IDs are monotonically increasing as long as a single
+// service instance serves them.
+//
+// Unlike a local generator, a remote generator may fail to obtain IDs because
+// the service is unreachable or refuses to generate IDs due to a clock
+// rollback, so all of its generation methods return an error in such a case.
+// Use [RemoteGenerator.Generate] or [RemoteGenerator.GenerateBatch] to fail
+// after one request, [RemoteGenerator.GenerateOrSleep] or
+// [RemoteGenerator.GenerateOrReset] to keep retrying for up to `Timeout`, or
+// [RemoteGenerator.GenerateOrSleepContext] to keep retrying until the context
+// is done. Hence, `GenerateOrSleep` and `GenerateOrReset` return an error
+// unlike their counterparts of [scru64.Generator], which cannot fail once the
+// generator is configured. The low-level `Core` methods are not offered because
+// the service determines the timestamp.
+//
+// This structure must be instantiated by [Client.NewRemoteGenerator].
+type RemoteGenerator struct {
+	// The maximum amount of time that a request to the service may take.
+	// Defaults to [DefaultTimeout]; may be changed before the first use.
+	Timeout time.Duration
+
+	client    *Client
+	batchSize int
+	nodeSpec  scru64.NodeSpec
+
+	lock   sync.Mutex
+	buffer []scru64.Id
+
+	// the channel closed when the in-flight fetch finishes, or nil if none
+	fetching chan struct{}
+
+	// the error of the last fetch, if it failed
+	err error
+}
+
+// Returns the `nodeId` of the service's generator.
+func (g *RemoteGenerator) NodeId() uint32 {
+	return g.nodeSpec.NodeId()
+}
+
+// Returns the size in bits of the `nodeId` of the service's generator.
+func (g *RemoteGenerator) NodeIdSize() uint8 {
+	return g.nodeSpec.NodeIdSize()
+}
+
+// Returns the node configuration of the service's generator.
+func (g *RemoteGenerator) NodeSpec() scru64.NodeSpec {
+	return g.nodeSpec
+}
+
+// Starts fetching the next batch in the background if the buffer runs low and
+// no fetch is in flight.
+//
+// The caller must hold the lock.
+func (g *RemoteGenerator) refill() {
+	if g.fetching != nil || len(g.buffer) > g.batchSize/4 {
+		return
+	}
+	done := make(chan struct{})
+	g.fetching = done
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), g.Timeout)
+		defer cancel()
+		ids, err := g.client.GenerateBatch(ctx, g.batchSize)
+
+		g.lock.Lock()
+		defer g.lock.Unlock()
+		g.buffer = append(g.buffer, ids...)
+		g.err = err
+		g.fetching = nil
+		close(done)
+	}()
+}
+
+// Takes up to `n` IDs from the buffer, waiting for a fetch if the buffer is
+// empty.
+func (g *RemoteGenerator) take(ctx context.Context, n int) ([]scru64.Id, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for len(g.buffer) == 0 {
+		g.refill()
+		done := g.fetching
+		g.lock.Unlock()
+		select {
+		case <-done:
+			g.lock.Lock()
+		case <-ctx.Done():
+			g.lock.Lock()
+			return nil, ctx.Err()
+		}
+
+		// retry if concurrent callers have consumed the fetched IDs
+		if len(g.buffer) == 0 && g.err != nil {
+			return nil, g.err
+		}
+	}
+
+	n = min(n, len(g.buffer))
+	ids := append([]scru64.Id{}, g.buffer[:n]...)
+	g.buffer = g.buffer[n:]
+	g.refill()
+	return ids, nil
+}
+
+// Returns a new SCRU64 ID object, or an error if the buffer is empty and the
+// service fails to provide IDs within one request.
+func (g *RemoteGenerator) Generate() (scru64.Id, error) {
+	ids, err := g.take(context.Background(), 1)
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// Returns `n` new SCRU64 ID objects, or an error along with the IDs obtained
+// before the service failed to provide IDs.
+//
+// This method panics if `n` is negative.
+func (g *RemoteGenerator) GenerateBatch(n int) ([]scru64.Id, error) {
+	if n < 0 {
+		panic("`n` must not be negative")
+	}
+	values := make([]scru64.Id, 0, n)
+	for len(values) < n {
+		ids, err := g.take(context.Background(), n-len(values))
+		if err != nil {
+			return values, err
+		}
+		values = append(values, ids...)
+	}
+	return values, nil
+}
+
+// Returns a new SCRU64 ID object, retrying failed requests until the context
+// is done, in which case this method returns an error that wraps both the
+// context's error and the last request error.
+func (g *RemoteGenerator) GenerateOrSleepContext(ctx context.Context) (scru64.Id, error) {
+	for {
+		ids, err := g.take(ctx, 1)
+		if err == nil {
+			return ids[0], nil
+		} else if ctx.Err() != nil {
+			return 0, err
+		}
+
+		timer := time.NewTimer(retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// Returns a new SCRU64 ID object, retrying failed requests for up to `Timeout`,
+// after which this method returns an error that wraps
+// `context.DeadlineExceeded`.
+func (g *RemoteGenerator) GenerateOrSleep() (scru64.Id, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.Timeout)
+	defer cancel()
+	return g.GenerateOrSleepContext(ctx)
+}
+
+// Returns a new SCRU64 ID object, retrying failed requests for up to `Timeout`.
+//
+// The service, not this generator, handles clock rollbacks (see [Server]), so
+// this method works the same as [RemoteGenerator.GenerateOrSleep] and never
+// resets the state of the service's generator.
+func (g *RemoteGenerator) GenerateOrReset() (scru64.Id, error) {
+	return g.GenerateOrSleep()
+}
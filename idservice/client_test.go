@@ -0,0 +1,151 @@
+package idservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Hands out monotonically increasing IDs fetched in batches.
+func TestRemoteGenerator(t *testing.T) {
+	s := NewServer(scru64.NewGeneratorParsing("42/8"))
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	ctx := context.Background()
+	g, err := NewClient(server.URL+"/").NewRemoteGenerator(ctx, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NodeId() != 42 || g.NodeIdSize() != 8 || g.NodeSpec().NodeId() != 42 {
+		t.Fatalf("unexpected node spec: %v", g.NodeSpec())
+	}
+
+	var prev scru64.Id
+	for i := 0; i < 1000; i++ {
+		var x scru64.Id
+		switch i % 4 {
+		case 0:
+			x, err = g.Generate()
+		case 1:
+			x, err = g.GenerateOrSleep()
+		case 2:
+			x, err = g.GenerateOrReset()
+		default:
+			x, err = g.GenerateOrSleepContext(ctx)
+		}
+		if err != nil || x <= prev || x.NodeCtr()>>16 != 42 {
+			t.Fatalf("unexpected result: %v, %v (prev %v)", x, err, prev)
+		}
+		prev = x
+	}
+
+	xs, err := g.GenerateBatch(250)
+	if err != nil || len(xs) != 250 || xs[0] <= prev {
+		t.Fatalf("unexpected batch: %v, %v", len(xs), err)
+	}
+	for i := 1; i < len(xs); i++ {
+		if xs[i-1] >= xs[i] {
+			t.Fatalf("non-increasing batch at %v", i)
+		}
+	}
+
+	// batches, not single IDs, are fetched
+	if n := s.Stats().Requests; n > 20 {
+		t.Fatalf("too many requests: %v", n)
+	}
+}
+
+// Safe for concurrent use.
+func TestRemoteGeneratorConcurrent(t *testing.T) {
+	server := httptest.NewServer(NewServer(scru64.NewGeneratorParsing("42/8")))
+	defer server.Close()
+	g, err := NewClient(server.URL).NewRemoteGenerator(context.Background(), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	seen := map[scru64.Id]struct{}{}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				x, err := g.Generate()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				lock.Lock()
+				seen[x] = struct{}{}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 2000 {
+		t.Fatalf("expected 2000 unique IDs, got %v", len(seen))
+	}
+}
+
+// Returns errors instead of blocking when the service is unavailable.
+func TestRemoteGeneratorUnavailable(t *testing.T) {
+	var lock sync.Mutex
+	var handler http.Handler = NewServer(scru64.NewGeneratorParsing("42/8"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		h := handler
+		lock.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	g, err := NewClient(server.URL).NewRemoteGenerator(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Timeout = 200 * time.Millisecond
+
+	lock.Lock()
+	handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"down"}`, http.StatusServiceUnavailable)
+	})
+	lock.Unlock()
+
+	// drain the buffer
+	xs, err := g.GenerateBatch(10)
+	if err == nil || len(xs) != 4 {
+		t.Fatalf("expected 4 IDs and error, got %v, %v", len(xs), err)
+	}
+	if _, err := g.Generate(); err == nil {
+		t.Fatal("expected error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := g.GenerateOrSleepContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if _, err := g.GenerateOrSleep(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if _, err := g.GenerateOrReset(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	server.Close()
+	if _, err := NewClient(server.URL).NewRemoteGenerator(context.Background(), 4); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := NewClient(server.URL).NewRemoteGenerator(context.Background(), 0); err == nil {
+		t.Fatal("expected error")
+	}
+}
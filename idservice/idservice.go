@@ -0,0 +1,35 @@
+// Package idservice serves SCRU64 IDs over HTTP.
+//
+// A [Server] exposes a single [scru64.Generator] to services that cannot embed
+// one, such as those written in other languages. The IDs handed out by one
+// server are monotonically increasing across all the clients, and a realm may
+// contain multiple servers as long as each has a distinct `nodeId`.
+package idservice
+
+import (
+	"github.com/scru64/go-scru64"
+)
+
+// The JSON response body of the single ID endpoint.
+type idResponse struct {
+	Id scru64.Id `json:"id"`
+}
+
+// The JSON response body of the batch endpoint.
+type idsResponse struct {
+	Ids []scru64.Id `json:"ids"`
+}
+
+// The JSON response body of the health endpoint.
+type healthResponse struct {
+	Status   string          `json:"status"`
+	NodeSpec scru64.NodeSpec `json:"node_spec"`
+}
+
+// The JSON response body of error responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// The default maximum number of IDs returned by a batch request.
+const DefaultMaxBatch = 10_000
@@ -0,0 +1,224 @@
+package idservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/scru64/go-scru64"
+)
+
+// An HTTP handler that generates SCRU64 IDs with a [scru64.Generator].
+//
+// The server exposes the following endpoints:
+//
+//	| Endpoint          | Text response              | JSON response            |
+//	| ----------------- | -------------------------- | ------------------------ |
+//	| GET /id           | ID and newline             | id                       |
+//	| GET /ids?n=100    | one ID per line            | ids                      |
+//	| GET /inspect?id=X | "field: value" lines       | [scru64.IdInfo]          |
+//	| GET /healthz      | "ok"                       | status, node_spec        |
+//	| GET /metrics      | Prometheus text exposition | (text only)              |
+//
+// The endpoints respond in plain text unless the request has the `format=json`
+// query parameter or an `Accept` header that includes "application/json". The
+// ID endpoints respond with 503 Service Unavailable and a `Retry-After` header
+// if the generator detects a significant clock rollback, and the batch
+// endpoint responds with 400 Bad Request if `n` is not between 1 and
+// `MaxBatch`. The ID endpoints accept GET only, so that HEAD requests do not
+// consume IDs; the other endpoints also accept HEAD.
+//
+// This structure must be instantiated by [NewServer].
+type Server struct {
+	// The maximum number of IDs returned by a batch request. Defaults to
+	// [DefaultMaxBatch]; may be changed before the server starts serving.
+	MaxBatch int
+
+	generator *scru64.Generator
+	mux       *http.ServeMux
+
+	requests       atomic.Uint64
+	idsGenerated   atomic.Uint64
+	clockRollbacks atomic.Uint64
+}
+
+// Represents the counters of a [Server].
+type Stats struct {
+	// The number of requests served.
+	Requests uint64
+
+	// The number of IDs generated and handed out.
+	IdsGenerated uint64
+
+	// The number of requests refused due to a significant clock rollback.
+	ClockRollbacks uint64
+}
+
+// Creates a new server generating IDs with `generator`.
+//
+// The generator should not be used elsewhere, or the IDs handed out by the
+// server are not monotonically increasing in the order of responses.
+func NewServer(generator *scru64.Generator) *Server {
+	s := &Server{
+		MaxBatch:  DefaultMaxBatch,
+		generator: generator,
+		mux:       http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/id", allowMethods(s.handleId, http.MethodGet))
+	s.mux.HandleFunc("/ids", allowMethods(s.handleIds, http.MethodGet))
+	s.mux.HandleFunc("/inspect", allowMethods(s.handleInspect, http.MethodGet, http.MethodHead))
+	s.mux.HandleFunc("/healthz", allowMethods(s.handleHealth, http.MethodGet, http.MethodHead))
+	s.mux.HandleFunc("/metrics", allowMethods(s.handleMetrics, http.MethodGet, http.MethodHead))
+	return s
+}
+
+// See http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	s.mux.ServeHTTP(w, r)
+}
+
+// Returns the current counters of the server.
+func (s *Server) Stats() Stats {
+	return Stats{
+		Requests:       s.requests.Load(),
+		IdsGenerated:   s.idsGenerated.Load(),
+		ClockRollbacks: s.clockRollbacks.Load(),
+	}
+}
+
+func (s *Server) handleId(w http.ResponseWriter, r *http.Request) {
+	id, err := s.generator.Generate()
+	if err != nil {
+		s.writeRollback(w, r)
+		return
+	}
+	s.idsGenerated.Add(1)
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, idResponse{Id: id})
+	} else {
+		writeText(w, http.StatusOK, id.String()+"\n")
+	}
+}
+
+func (s *Server) handleIds(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+	if err != nil || n < 1 || n > s.MaxBatch {
+		writeError(w, r, http.StatusBadRequest,
+			fmt.Sprintf("`n` must be an integer from 1 to %v", s.MaxBatch))
+		return
+	}
+
+	ids, err := s.generator.GenerateBatch(n)
+	if err != nil {
+		// IDs generated before the rollback are discarded, which is harmless
+		s.writeRollback(w, r)
+		return
+	}
+	s.idsGenerated.Add(uint64(n))
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, idsResponse{Ids: ids})
+		return
+	}
+	var b strings.Builder
+	b.Grow(13 * n)
+	for _, id := range ids {
+		b.WriteString(id.String())
+		b.WriteByte('\n')
+	}
+	writeText(w, http.StatusOK, b.String())
+}
+
+func (s *Server) handleInspect(w http.ResponseWriter, r *http.Request) {
+	id, err := scru64.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	info := id.Inspect(s.generator.NodeIdSize())
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, info)
+		return
+	}
+	writeText(w, http.StatusOK, fmt.Sprintf(
+		"id: %v\ntimestamp: %v\ntime: %v\nnodeCtr: %v\nnodeIdSize: %v\nnodeId: %v\ncounter: %v\n",
+		info.Id, info.Timestamp, info.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		info.NodeCtr, info.NodeIdSize, info.NodeId, info.Counter))
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, healthResponse{Status: "ok", NodeSpec: s.generator.NodeSpec()})
+	} else {
+		writeText(w, http.StatusOK, "ok\n")
+	}
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	stats := s.Stats()
+	var b strings.Builder
+	metric := func(name string, help string, value uint64) {
+		fmt.Fprintf(&b, "# HELP %v %v\n# TYPE %v counter\n%v %v\n", name, help, name, name, value)
+	}
+	metric("scru64_requests_total", "Number of requests served.", stats.Requests)
+	metric("scru64_ids_generated_total", "Number of IDs handed out.", stats.IdsGenerated)
+	metric("scru64_clock_rollbacks_total",
+		"Number of requests refused due to clock rollback.", stats.ClockRollbacks)
+	writeText(w, http.StatusOK, b.String())
+}
+
+// Writes the response to a request refused due to a significant clock rollback.
+func (s *Server) writeRollback(w http.ResponseWriter, r *http.Request) {
+	s.clockRollbacks.Add(1)
+	w.Header().Set("Retry-After", "1")
+	writeError(w, r, http.StatusServiceUnavailable, scru64.ErrClockRollback.Error())
+}
+
+// Wraps a handler to reject requests with methods other than `methods`.
+func allowMethods(h http.HandlerFunc, methods ...string) http.HandlerFunc {
+	allow := strings.Join(methods, ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(methods, r.Method) {
+			w.Header().Set("Allow", allow)
+			writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// Returns whether the client prefers a JSON response.
+func wantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// Writes a JSON response.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Writes a plain text response.
+func writeText(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(body))
+}
+
+// Writes an error response in the format preferred by the client.
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if wantsJSON(r) {
+		writeJSON(w, status, errorResponse{Error: message})
+	} else {
+		writeText(w, status, message+"\n")
+	}
+}
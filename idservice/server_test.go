@@ -0,0 +1,143 @@
+package idservice
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Sends a GET request to the server and returns the status code and body.
+func get(t *testing.T, h http.Handler, target string, accept string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	body, _ := io.ReadAll(rec.Body)
+	return rec.Code, string(body)
+}
+
+// Serves monotonically increasing IDs in text and JSON.
+func TestServer(t *testing.T) {
+	s := NewServer(scru64.NewGeneratorParsing("42/8"))
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	first, err := scru64.Parse(strings.TrimSpace(string(body)))
+	if resp.StatusCode != http.StatusOK || err != nil || first.NodeCtr()>>16 != 42 {
+		t.Fatalf("unexpected response: %v %q", resp.StatusCode, body)
+	}
+
+	code, body2 := get(t, s, "/id", "application/json")
+	var single idResponse
+	if err := json.Unmarshal([]byte(body2), &single); code != http.StatusOK || err != nil ||
+		single.Id <= first {
+		t.Fatalf("unexpected response: %v %q", code, body2)
+	}
+
+	code, text := get(t, s, "/ids?n=100", "")
+	lines := strings.Fields(text)
+	if code != http.StatusOK || len(lines) != 100 || lines[0] <= single.Id.String() {
+		t.Fatalf("unexpected response: %v %q", code, text)
+	}
+	code, text = get(t, s, "/ids?n=3&format=json", "")
+	var batch idsResponse
+	if err := json.Unmarshal([]byte(text), &batch); code != http.StatusOK || err != nil ||
+		len(batch.Ids) != 3 || batch.Ids[0].String() <= lines[99] {
+		t.Fatalf("unexpected response: %v %q", code, text)
+	}
+
+	for _, target := range []string{"/ids", "/ids?n=0", "/ids?n=10001", "/inspect?id=x"} {
+		if code, _ := get(t, s, target, ""); code != http.StatusBadRequest {
+			t.Fatalf("%v: expected 400, got %v", target, code)
+		}
+	}
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/id", nil),
+		httptest.NewRequest(http.MethodHead, "/id", nil),
+		httptest.NewRequest(http.MethodHead, "/ids?n=10", nil),
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET" {
+			t.Fatalf("%v %v: expected 405, got %v", req.Method, req.URL, rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", rec.Code)
+	}
+
+	stats := s.Stats()
+	if stats.Requests != 12 || stats.IdsGenerated != 105 || stats.ClockRollbacks != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	code, text = get(t, s, "/metrics", "")
+	if code != http.StatusOK || !strings.Contains(text, "\nscru64_ids_generated_total 105\n") {
+		t.Fatalf("unexpected metrics: %q", text)
+	}
+}
+
+// Decodes IDs and reports health.
+func TestServerInspect(t *testing.T) {
+	s := NewServer(scru64.NewGeneratorParsing("42/8"))
+	x, _ := scru64.FromParts(0x1234567890, 42<<16|0x0123)
+
+	code, text := get(t, s, "/inspect?id="+x.String(), "")
+	if code != http.StatusOK || !strings.Contains(text, "nodeId: 42\ncounter: 291\n") {
+		t.Fatalf("unexpected response: %v %q", code, text)
+	}
+	code, text = get(t, s, "/inspect?id="+x.String()+"&format=json", "")
+	var info scru64.IdInfo
+	if err := json.Unmarshal([]byte(text), &info); code != http.StatusOK || err != nil ||
+		info.Id != x || info.NodeIdSize != 8 || info.Counter != 291 {
+		t.Fatalf("unexpected response: %v %q", code, text)
+	}
+
+	y, _ := scru64.FromParts(0x1234567890, 0x0123)
+	code, text = get(t, s, "/inspect?id="+y.String()+"&format=json", "")
+	if code != http.StatusOK || !strings.Contains(text, `"nodeIdSize":8,"nodeId":0,"counter":291`) {
+		t.Fatalf("unexpected response: %v %q", code, text)
+	}
+
+	code, text = get(t, s, "/healthz", "application/json")
+	var health healthResponse
+	if err := json.Unmarshal([]byte(text), &health); code != http.StatusOK || err != nil ||
+		health.Status != "ok" || health.NodeSpec.NodeId() != 42 {
+		t.Fatalf("unexpected response: %v %q", code, text)
+	}
+}
+
+// Refuses to generate IDs upon clock rollback.
+func TestServerClockRollback(t *testing.T) {
+	g := scru64.NewGeneratorParsing("42/8")
+	g.GenerateOrResetCore(uint64(time.Now().Add(time.Minute).UnixMilli()), 10_000)
+	s := NewServer(g)
+
+	for _, target := range []string{"/id", "/ids?n=10"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+			t.Fatalf("%v: expected 503, got %v", target, rec.Code)
+		}
+	}
+	if stats := s.Stats(); stats.ClockRollbacks != 2 || stats.IdsGenerated != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,251 @@
+package lease
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// The error value returned by [Lease.Generate] and [Lease.GenerateOrSleep] when
+// the lease has been lost, released, or expired.
+var ErrLeaseLost = errors.New("lease.Lease: lease lost")
+
+// A client of the coordinator [Server].
+type Client struct {
+	// The base URL of the coordinator (e.g., "http://coordinator:8064").
+	BaseURL string
+
+	// The HTTP client used to send requests. Defaults to `http.DefaultClient`.
+	HTTPClient *http.Client
+}
+
+// Creates a new client of the coordinator at `baseURL`.
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Sends a POST request to the endpoint and decodes the JSON response.
+func (c *Client) post(
+	ctx context.Context, endpoint string, body any, result any) (int, error) {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return 0, err
+		}
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, c.BaseURL+endpoint, &reqBody)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		var e errorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+		return resp.StatusCode, fmt.Errorf("%v: %v", resp.Status, e.Error)
+	} else if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return resp.StatusCode, fmt.Errorf("invalid response body: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// Acquires a new lease from the coordinator and starts renewing it in the
+// background.
+//
+// The caller must call [Lease.Release] when it no longer needs the lease, so
+// that the background renewal stops and the `nodeId` is returned to the
+// coordinator.
+func (c *Client) Acquire(ctx context.Context) (*Lease, error) {
+	sentAt := time.Now()
+	var resp leaseResponse
+	if _, err := c.post(ctx, "/acquire", nil, &resp); err != nil {
+		return nil, fmt.Errorf("lease.Client: could not acquire lease: %w", err)
+	}
+	ttl := time.Duration(resp.TTLMs) * time.Millisecond
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lease.Client: could not acquire lease: invalid TTL (%v)", ttl)
+	}
+
+	l := &Lease{
+		client:     c,
+		leaseId:    resp.LeaseId,
+		nodeSpec:   resp.NodeSpec,
+		ttl:        ttl,
+		generator:  scru64.NewGenerator(resp.NodeSpec),
+		validUntil: sentAt.Add(ttl),
+		lost:       make(chan struct{}),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go l.heartbeat()
+	return l, nil
+}
+
+// Represents a `nodeId` lease acquired by [Client.Acquire].
+//
+// A lease embeds a [scru64.Generator] configured with the leased `nodeId`, and
+// it generates IDs only while the lease is valid. The lease is considered valid
+// until one TTL after the last successful renewal request was sent, which never
+// exceeds the expiry recorded by the coordinator. Once the lease is lost, it
+// cannot be recovered; acquire a new lease instead.
+type Lease struct {
+	client    *Client
+	leaseId   string
+	nodeSpec  scru64.NodeSpec
+	ttl       time.Duration
+	generator *scru64.Generator
+
+	lock       sync.Mutex
+	validUntil time.Time
+	lastId     *scru64.Id
+	err        error
+	lost       chan struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+// Returns the node configuration of the leased `nodeId`.
+func (l *Lease) NodeSpec() scru64.NodeSpec {
+	return l.nodeSpec
+}
+
+// Returns a channel that is closed when the lease is lost or released.
+func (l *Lease) Done() <-chan struct{} {
+	return l.lost
+}
+
+// Returns the reason why the lease was lost, or nil if the lease is valid.
+func (l *Lease) Err() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.err
+}
+
+// Marks the lease as lost with the reason.
+//
+// The caller must hold the lock.
+func (l *Lease) markLost(err error) {
+	if l.err == nil {
+		l.err = err
+		close(l.lost)
+	}
+}
+
+// Renews the lease periodically until it is lost or released.
+func (l *Lease) heartbeat() {
+	defer close(l.stopped)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.lost:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+		sentAt := time.Now()
+		var resp leaseResponse
+		status, err := l.client.post(
+			ctx, "/renew", leaseRequest{LeaseId: l.leaseId}, &resp)
+		cancel()
+
+		l.lock.Lock()
+		if err == nil {
+			l.validUntil = sentAt.Add(l.ttl)
+		} else if status == http.StatusGone {
+			l.markLost(fmt.Errorf("%w: %v", ErrLeaseLost, err))
+		} else if !time.Now().Before(l.validUntil) {
+			l.markLost(fmt.Errorf("%w: could not renew before expiry: %v", ErrLeaseLost, err))
+		}
+		l.lock.Unlock()
+	}
+}
+
+// Generates a new SCRU64 ID if the lease is valid.
+//
+// This method returns an error wrapping [ErrLeaseLost] if the lease is no
+// longer valid, or [scru64.ErrClockRollback] upon significant clock rollback.
+func (l *Lease) Generate() (scru64.Id, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.err != nil {
+		return 0, l.err
+	} else if !time.Now().Before(l.validUntil) {
+		l.markLost(fmt.Errorf("%w: expired", ErrLeaseLost))
+		return 0, l.err
+	}
+
+	x, err := l.generator.Generate()
+	if err == nil {
+		l.lastId = &x
+	}
+	return x, err
+}
+
+// Generates a new SCRU64 ID if the lease is valid, or sleeps and waits for one
+// if not immediately available.
+//
+// This method returns an error wrapping [ErrLeaseLost] if the lease is no
+// longer valid.
+func (l *Lease) GenerateOrSleep() (scru64.Id, error) {
+	for {
+		x, err := l.Generate()
+		if err == scru64.ErrClockRollback {
+			time.Sleep(64 * time.Millisecond)
+		} else {
+			return x, err
+		}
+	}
+}
+
+// Stops generating IDs and returns the leased `nodeId` to the coordinator.
+//
+// The last ID generated under the lease is reported to the coordinator so that
+// it can shorten the quarantine of the `nodeId`. This method returns a non-nil
+// error if the coordinator could not be notified; the lease is no longer usable
+// in either case.
+func (l *Lease) Release(ctx context.Context) error {
+	l.lock.Lock()
+	alreadyLost := l.err != nil
+	l.markLost(fmt.Errorf("%w: released", ErrLeaseLost))
+	lastId := l.lastId
+	l.lock.Unlock()
+
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.stopped
+
+	if alreadyLost {
+		return nil
+	}
+	req := leaseRequest{LeaseId: l.leaseId, LastId: lastId}
+	if _, err := l.client.post(ctx, "/release", req, nil); err != nil {
+		return fmt.Errorf("lease.Lease: could not release lease: %w", err)
+	}
+	return nil
+}
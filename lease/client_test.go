@@ -0,0 +1,77 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Renews leases in the background and generates IDs while valid.
+func TestClient(t *testing.T) {
+	s, _ := NewServer(8, 300*time.Millisecond, 0)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	x, err := c.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := c.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.NodeSpec().NodeId() == y.NodeSpec().NodeId() {
+		t.Fatalf("duplicate nodeId: %v", x.NodeSpec())
+	}
+
+	// outlive several TTLs through renewal
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		id, err := x.GenerateOrSleep()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.NodeCtr()>>16 != x.NodeSpec().NodeId() {
+			t.Fatalf("unexpected nodeId: %v", id)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := y.Release(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := y.Generate(); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost, got %v", err)
+	}
+	if err := y.Release(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// stop generating once coordinator becomes unreachable
+	ts.CloseClientConnections()
+	ts.Close()
+	select {
+	case <-x.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("lease not lost")
+	}
+	if _, err := x.Generate(); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost, got %v", err)
+	}
+	if !errors.Is(x.Err(), ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost, got %v", x.Err())
+	}
+	x.Release(context.Background())
+}
+
+// Fails to acquire a lease from an unreachable coordinator.
+func TestClientError(t *testing.T) {
+	ts := httptest.NewServer(nil)
+	ts.Close()
+	if _, err := NewClient(ts.URL).Acquire(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,48 @@
+// Package lease implements a lease-based `nodeId` coordinator for SCRU64 ID
+// generators.
+//
+// A [Server] hands out `nodeId` leases of a fixed `nodeIdSize` over HTTP, and a
+// [Client] acquires a lease, renews it in the background, and generates SCRU64
+// IDs only while the lease is valid. This allows autoscaled fleets to share a
+// realm without manual `nodeId` assignment.
+//
+// The server keeps released and expired `nodeId` values in quarantine until
+// the last timestamp that their former holders could have embedded in IDs has
+// passed, so that a new holder never generates IDs that collide with the ones
+// generated by the former holder.
+package lease
+
+import (
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// The request body of the renew and release endpoints.
+type leaseRequest struct {
+	LeaseId string `json:"lease_id"`
+
+	// The last ID generated under the lease, reported on release to shorten the
+	// quarantine.
+	LastId *scru64.Id `json:"last_id,omitempty"`
+}
+
+// The response body of the acquire and renew endpoints.
+type leaseResponse struct {
+	LeaseId  string          `json:"lease_id"`
+	NodeSpec scru64.NodeSpec `json:"node_spec"`
+	TTLMs    int64           `json:"ttl_ms"`
+}
+
+// The response body of error responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// The default lifetime of a lease without renewal.
+const DefaultTTL = 30 * time.Second
+
+// The default maximum amount of time by which a generator's timestamp can lead
+// the wall clock, which corresponds to the default rollback allowance of
+// [scru64.Generator] plus one timestamp tick.
+const DefaultMaxLead = 10_000*time.Millisecond + 256*time.Millisecond
@@ -0,0 +1,251 @@
+package lease
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// An HTTP handler that hands out `nodeId` leases within a `nodeIdSize`.
+//
+// The server exposes the following endpoints, all of which accept and return
+// JSON:
+//
+//	| Endpoint       | Request body              | Response                      |
+//	| -------------- | ------------------------- | ----------------------------- |
+//	| POST /acquire  | (none)                    | lease_id, node_spec, ttl_ms   |
+//	| POST /renew    | lease_id                  | lease_id, node_spec, ttl_ms   |
+//	| POST /release  | lease_id, optional last_id| 204 No Content                |
+//
+// The renew endpoint responds with 410 Gone if the lease has expired or been
+// released, and the acquire endpoint responds with 503 Service Unavailable if
+// all `nodeId` values are leased or quarantined or if the server is warming up.
+//
+// This structure must be instantiated by [NewServer]. The lease state is kept
+// in memory, so restarting the server forgets all the leases; a restarted
+// server should not start handing out leases until one TTL plus `MaxLead` has
+// passed, which `WarmUpUntil` enforces.
+type Server struct {
+	// Returns the current time. Defaults to `time.Now`; tests may replace it
+	// before the server starts serving.
+	Now func() time.Time
+
+	// The time until which the acquire endpoint refuses new leases with a
+	// `Retry-After` header. Zero by default; may be set before the server starts
+	// serving.
+	WarmUpUntil time.Time
+
+	nodeIdSize uint8
+	ttl        time.Duration
+	maxLead    time.Duration
+	mux        *http.ServeMux
+
+	lock sync.Mutex
+
+	// active leases by lease ID
+	leases map[string]*activeLease
+
+	// `nodeId` values in use by active leases
+	inUse map[uint32]string
+
+	// `nodeId` values in quarantine and the time they become available
+	quarantine map[uint32]time.Time
+}
+
+// The state of an active lease.
+type activeLease struct {
+	nodeId    uint32
+	expiresAt time.Time
+}
+
+// Creates a new server handing out leases of `nodeIdSize`-bit `nodeId` values
+// with the given TTL.
+//
+// `maxLead` is the maximum amount of time by which the timestamps of the
+// clients' generators can lead the wall clock (see [DefaultMaxLead]); a
+// released or expired `nodeId` is quarantined for this period.
+//
+// This function returns a non-nil error if `nodeIdSize` is zero or greater than
+// 23 or if `ttl` is not positive.
+func NewServer(
+	nodeIdSize uint8, ttl time.Duration, maxLead time.Duration) (*Server, error) {
+	if _, err := scru64.NewNodeSpecWithNodeId(0, nodeIdSize); err != nil {
+		return nil, err
+	} else if ttl <= 0 {
+		return nil, fmt.Errorf("lease.Server: `ttl` (%v) must be positive", ttl)
+	} else if maxLead < 0 {
+		return nil, fmt.Errorf("lease.Server: `maxLead` (%v) must not be negative", maxLead)
+	}
+
+	s := &Server{
+		Now:        time.Now,
+		nodeIdSize: nodeIdSize,
+		ttl:        ttl,
+		maxLead:    maxLead,
+		mux:        http.NewServeMux(),
+		leases:     map[string]*activeLease{},
+		inUse:      map[uint32]string{},
+		quarantine: map[uint32]time.Time{},
+	}
+	s.mux.HandleFunc("/acquire", postOnly(s.handleAcquire))
+	s.mux.HandleFunc("/renew", postOnly(s.handleRenew))
+	s.mux.HandleFunc("/release", postOnly(s.handleRelease))
+	return s, nil
+}
+
+// See http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Moves expired leases to quarantine and drops elapsed quarantine entries.
+//
+// The caller must hold the lock.
+func (s *Server) sweep(now time.Time) {
+	for leaseId, e := range s.leases {
+		if !now.Before(e.expiresAt) {
+			// the holder may have generated IDs until the expiry
+			s.quarantine[e.nodeId] = e.expiresAt.Add(s.maxLead)
+			delete(s.inUse, e.nodeId)
+			delete(s.leases, leaseId)
+		}
+	}
+	for nodeId, until := range s.quarantine {
+		if !now.Before(until) {
+			delete(s.quarantine, nodeId)
+		}
+	}
+}
+
+func (s *Server) handleAcquire(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.Now()
+	if now.Before(s.WarmUpUntil) {
+		w.Header().Set("Retry-After", fmt.Sprint(int(s.WarmUpUntil.Sub(now).Seconds())+1))
+		writeError(w, http.StatusServiceUnavailable, "warming up")
+		return
+	}
+	s.sweep(now)
+
+	for nodeId := uint32(0); nodeId < (1 << s.nodeIdSize); nodeId++ {
+		if _, ok := s.inUse[nodeId]; ok {
+			continue
+		} else if _, ok := s.quarantine[nodeId]; ok {
+			continue
+		}
+
+		leaseId, err := newLeaseId()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.leases[leaseId] = &activeLease{nodeId: nodeId, expiresAt: now.Add(s.ttl)}
+		s.inUse[nodeId] = leaseId
+		s.writeLease(w, leaseId, nodeId)
+		return
+	}
+	writeError(w, http.StatusServiceUnavailable, "no `nodeId` available")
+}
+
+func (s *Server) handleRenew(w http.ResponseWriter, r *http.Request) {
+	var req leaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.Now()
+	s.sweep(now)
+
+	e, ok := s.leases[req.LeaseId]
+	if !ok {
+		writeError(w, http.StatusGone, "lease expired or unknown")
+		return
+	}
+	e.expiresAt = now.Add(s.ttl)
+	s.writeLease(w, req.LeaseId, e.nodeId)
+}
+
+func (s *Server) handleRelease(w http.ResponseWriter, r *http.Request) {
+	var req leaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.Now()
+	s.sweep(now)
+
+	e, ok := s.leases[req.LeaseId]
+	if !ok {
+		writeError(w, http.StatusGone, "lease expired or unknown")
+		return
+	}
+
+	until := now.Add(s.maxLead)
+	if req.LastId != nil {
+		// IDs are no longer generated, so the last ID bounds all timestamps used
+		lastTick := time.UnixMilli(int64(req.LastId.Timestamp()+1) << 8)
+		if lastTick.Before(until) {
+			until = lastTick
+		}
+	}
+	s.quarantine[e.nodeId] = until
+	delete(s.inUse, e.nodeId)
+	delete(s.leases, req.LeaseId)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Writes a successful lease response.
+func (s *Server) writeLease(w http.ResponseWriter, leaseId string, nodeId uint32) {
+	nodeSpec, _ := scru64.NewNodeSpecWithNodeId(nodeId, s.nodeIdSize)
+	writeJSON(w, http.StatusOK, leaseResponse{
+		LeaseId:  leaseId,
+		NodeSpec: nodeSpec,
+		TTLMs:    s.ttl.Milliseconds(),
+	})
+}
+
+// Wraps a handler to reject requests other than POST.
+func postOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// Generates a random lease ID.
+func newLeaseId() (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", fmt.Errorf("lease.Server: could not generate lease ID: %w", err)
+	}
+	return hex.EncodeToString(buf[:]), nil
+}
+
+// Writes a JSON response.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Writes a JSON error response.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
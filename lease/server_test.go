@@ -0,0 +1,121 @@
+package lease
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Sends a POST request to the handler and decodes the response.
+func post(t *testing.T, h http.Handler, endpoint string, body any) (int, leaseResponse) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, endpoint, &buf))
+	var resp leaseResponse
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code, resp
+}
+
+// Hands out distinct `nodeId` values and quarantines released ones.
+func TestServer(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	s, err := NewServer(2, 30*time.Second, DefaultMaxLead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Now = func() time.Time { return now }
+
+	seen := map[uint32]string{}
+	for i := 0; i < 4; i++ {
+		code, resp := post(t, s, "/acquire", nil)
+		if code != http.StatusOK || resp.TTLMs != 30_000 || resp.NodeSpec.NodeIdSize() != 2 {
+			t.Fatalf("unexpected response: %v %+v", code, resp)
+		}
+		if _, ok := seen[resp.NodeSpec.NodeId()]; ok {
+			t.Fatalf("duplicate nodeId: %v", resp.NodeSpec)
+		}
+		seen[resp.NodeSpec.NodeId()] = resp.LeaseId
+	}
+	if code, _ := post(t, s, "/acquire", nil); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %v", code)
+	}
+
+	// released `nodeId` is quarantined until the last ID's tick has passed
+	lastId, _ := scru64.FromParts(uint64(now.Add(2*time.Second).UnixMilli())>>8, 0)
+	if code, _ := post(t, s, "/release", leaseRequest{LeaseId: seen[1], LastId: &lastId}); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %v", code)
+	}
+	if code, _ := post(t, s, "/renew", leaseRequest{LeaseId: seen[1]}); code != http.StatusGone {
+		t.Fatalf("expected 410, got %v", code)
+	}
+	if code, _ := post(t, s, "/acquire", nil); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %v", code)
+	}
+	now = now.Add(3 * time.Second)
+	if code, resp := post(t, s, "/acquire", nil); code != http.StatusOK || resp.NodeSpec.NodeId() != 1 {
+		t.Fatalf("unexpected response: %v %+v", code, resp)
+	}
+
+	// renewed leases survive while others expire and enter quarantine
+	now = now.Add(20 * time.Second)
+	if code, _ := post(t, s, "/renew", leaseRequest{LeaseId: seen[0]}); code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", code)
+	}
+	now = now.Add(10 * time.Second)
+	if code, _ := post(t, s, "/renew", leaseRequest{LeaseId: seen[2]}); code != http.StatusGone {
+		t.Fatalf("expected 410, got %v", code)
+	}
+	if code, _ := post(t, s, "/acquire", nil); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %v", code)
+	}
+	now = now.Add(DefaultMaxLead)
+	if code, resp := post(t, s, "/acquire", nil); code != http.StatusOK || resp.NodeSpec.NodeId() == 0 {
+		t.Fatalf("unexpected response: %v %+v", code, resp)
+	}
+}
+
+// Rejects invalid configurations and requests.
+func TestServerError(t *testing.T) {
+	if _, err := NewServer(0, time.Second, 0); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := NewServer(8, 0, 0); err == nil {
+		t.Fatal("expected error")
+	}
+
+	s, _ := NewServer(8, time.Second, 0)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/acquire", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %v", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/renew", bytes.NewReader([]byte("{"))))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %v", rec.Code)
+	}
+
+	// refuse leases with a JSON error while warming up
+	s.WarmUpUntil = time.Now().Add(time.Minute)
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/acquire", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" ||
+		rec.Header().Get("Content-Type") != "application/json" ||
+		!strings.Contains(rec.Body.String(), `"warming up"`) {
+		t.Fatalf("unexpected response: %v %v %q", rec.Code, rec.Header(), rec.Body)
+	}
+}
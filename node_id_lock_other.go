@@ -0,0 +1,13 @@
+//go:build !unix
+
+package scru64
+
+import (
+	"errors"
+	"os"
+)
+
+// Reports that file locking is not supported on the platform.
+func tryLockFile(file *os.File) (bool, error) {
+	return false, errors.ErrUnsupported
+}
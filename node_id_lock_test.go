@@ -0,0 +1,82 @@
+//go:build unix
+
+package scru64
+
+import (
+	"sync"
+	"testing"
+)
+
+// Claims distinct `nodeId` values and reuses released ones.
+func TestLockNodeId(t *testing.T) {
+	dir := t.TempDir()
+
+	var locks []*NodeIdLock
+	for i := uint32(0); i < 4; i++ {
+		l, err := LockNodeId(dir, 8, 40, 43)
+		assert(t, err == nil)
+		assert(t, l.NodeSpec().NodeId() == 40+i && l.NodeSpec().NodeIdSize() == 8)
+		locks = append(locks, l)
+	}
+
+	_, err := LockNodeId(dir, 8, 40, 43)
+	assert(t, err != nil)
+
+	assert(t, locks[2].Release() == nil)
+	assert(t, locks[2].Release() == nil)
+	l, err := LockNodeId(dir, 8, 40, 43)
+	assert(t, err == nil && l.NodeSpec().NodeId() == 42)
+	assert(t, l.NewGenerator().NodeId() == 42)
+	locks[2] = l
+
+	for _, e := range locks {
+		assert(t, e.Release() == nil)
+	}
+
+	set, _ := ParseNodeSpecSet("0x28-0x29/8")
+	l, err = LockNodeIdInSet(dir, set)
+	assert(t, err == nil && l.NodeSpec().NodeId() == 40 && set.Contains(l.NodeSpec()))
+	assert(t, l.Release() == nil)
+}
+
+// Hands out unique `nodeId` values to concurrent allocators.
+func TestLockNodeIdConcurrent(t *testing.T) {
+	const nWorkers = 16
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	results := make(chan *NodeIdLock, nWorkers)
+	for i := 0; i < nWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l, err := LockNodeId(dir, 12, 0, nWorkers-1)
+			assert(t, err == nil)
+			results <- l
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	seen := map[uint32]bool{}
+	for l := range results {
+		if l == nil {
+			continue
+		}
+		assert(t, !seen[l.NodeSpec().NodeId()])
+		seen[l.NodeSpec().NodeId()] = true
+		l.Release()
+	}
+	assert(t, len(seen) == nWorkers)
+}
+
+// Rejects invalid ranges.
+func TestLockNodeIdError(t *testing.T) {
+	dir := t.TempDir()
+	_, err := LockNodeId(dir, 0, 0, 0)
+	assert(t, err != nil)
+	_, err = LockNodeId(dir, 8, 2, 1)
+	assert(t, err != nil)
+	_, err = LockNodeId(dir, 8, 0, 256)
+	assert(t, err != nil)
+}
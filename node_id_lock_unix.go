@@ -0,0 +1,24 @@
+//go:build unix
+
+package scru64
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Takes an exclusive lock on the file without blocking, returning false if the
+// file is locked by another open file description.
+func tryLockFile(file *os.File) (bool, error) {
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return true, nil
+		} else if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false, nil
+		} else if !errors.Is(err, syscall.EINTR) {
+			return false, err
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package scru64
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A regular expression representing the valid names of layout fields.
+var reNodeLayoutFieldName = regexp.MustCompile(`^[A-Za-z_][0-9A-Za-z_]*$`)
+
+// Represents a named bit field of a [NodeLayout].
+type NodeLayoutField struct {
+	// The name of the field.
+	Name string
+
+	// The size in bits of the field.
+	Size uint8
+}
+
+// Represents a division of the `nodeId` into named bit fields.
+//
+// A `NodeLayout` is usually expressed as a layout string, which lists the
+// fields from the most significant to the least significant, each consisting of
+// a name and a decimal size separated by a colon, joined by commas (e.g.,
+// "region:3,cluster:4,host:5"). The `nodeIdSize` of a layout is the sum of the
+// field sizes, which must range from 1 to 23.
+type NodeLayout struct {
+	fields     []NodeLayoutField
+	nodeIdSize uint8
+}
+
+// Creates an instance of [NodeLayout] from fields listed from the most
+// significant to the least significant.
+//
+// This function returns a non-nil error if no field is given, if any field has
+// an invalid or duplicate name or a zero size, or if the total size exceeds 23.
+func NewNodeLayout(fields ...NodeLayoutField) (NodeLayout, error) {
+	if len(fields) == 0 {
+		return NodeLayout{}, fmt.Errorf("scru64.NodeLayout: no field given")
+	}
+
+	seen := map[string]bool{}
+	var total uint = 0
+	for _, e := range fields {
+		if !reNodeLayoutFieldName.MatchString(e.Name) {
+			return NodeLayout{}, fmt.Errorf("scru64.NodeLayout: invalid field name %q", e.Name)
+		} else if seen[e.Name] {
+			return NodeLayout{}, fmt.Errorf("scru64.NodeLayout: duplicate field name %q", e.Name)
+		} else if e.Size == 0 {
+			return NodeLayout{}, fmt.Errorf("scru64.NodeLayout: field %q has zero size", e.Name)
+		}
+		seen[e.Name] = true
+		total += uint(e.Size)
+	}
+	if total >= uint(nodeCtrSize) {
+		return NodeLayout{}, fmt.Errorf(
+			"scru64.NodeLayout: total size of fields (%v) must range from 1 to 23", total)
+	}
+
+	return NodeLayout{
+		fields:     append([]NodeLayoutField(nil), fields...),
+		nodeIdSize: uint8(total),
+	}, nil
+}
+
+// Creates an instance of [NodeLayout] from a layout string (e.g.,
+// "region:3,cluster:4,host:5").
+//
+// This function returns a non-nil error if an invalid layout string is passed.
+func ParseNodeLayout(value string) (NodeLayout, error) {
+	var l NodeLayout
+	return l, l.UnmarshalText([]byte(value))
+}
+
+// Returns a copy of the fields listed from the most significant to the least
+// significant.
+func (l NodeLayout) Fields() []NodeLayoutField {
+	return append([]NodeLayoutField(nil), l.fields...)
+}
+
+// Returns the `nodeIdSize` value, i.e., the total size of the fields.
+func (l NodeLayout) NodeIdSize() uint8 {
+	return l.nodeIdSize
+}
+
+// Ensures that `values` contains exactly the fields of the layout and that each
+// value fits in the field.
+func (l NodeLayout) Validate(values map[string]uint32) error {
+	if len(l.fields) == 0 {
+		return fmt.Errorf("scru64.NodeLayout: method call on empty layout")
+	}
+	for _, e := range l.fields {
+		v, ok := values[e.Name]
+		if !ok {
+			return fmt.Errorf("scru64.NodeLayout: missing value for field %q", e.Name)
+		} else if v >= (1 << e.Size) {
+			return fmt.Errorf(
+				"scru64.NodeLayout: value (%v) of field %q must fit in %v bits",
+				v, e.Name, e.Size)
+		}
+	}
+	if len(values) != len(l.fields) {
+		for name := range values {
+			if !l.hasField(name) {
+				return fmt.Errorf("scru64.NodeLayout: unknown field %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// Reports whether the layout has a field named `name`.
+func (l NodeLayout) hasField(name string) bool {
+	for _, e := range l.fields {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Combines the field values into a `nodeId`.
+//
+// This method returns a non-nil error if `values` does not pass
+// [NodeLayout.Validate].
+func (l NodeLayout) NodeId(values map[string]uint32) (uint32, error) {
+	if err := l.Validate(values); err != nil {
+		return 0, err
+	}
+	var nodeId uint32 = 0
+	for _, e := range l.fields {
+		nodeId = nodeId<<e.Size | values[e.Name]
+	}
+	return nodeId, nil
+}
+
+// Creates an instance of [NodeSpec] with the `nodeId` combined from the field
+// values and the `nodeIdSize` of the layout.
+//
+// This method returns a non-nil error if `values` does not pass
+// [NodeLayout.Validate].
+func (l NodeLayout) NodeSpec(values map[string]uint32) (NodeSpec, error) {
+	nodeId, err := l.NodeId(values)
+	if err != nil {
+		return NodeSpec{}, err
+	}
+	return NewNodeSpecWithNodeId(nodeId, l.nodeIdSize)
+}
+
+// Splits a `nodeId` into field values.
+//
+// This method returns a non-nil error if the `nodeId` does not fit in the
+// `nodeIdSize` of the layout.
+func (l NodeLayout) Decode(nodeId uint32) (map[string]uint32, error) {
+	if len(l.fields) == 0 {
+		return nil, fmt.Errorf("scru64.NodeLayout: method call on empty layout")
+	} else if nodeId >= (1 << l.nodeIdSize) {
+		return nil, fmt.Errorf(
+			"scru64.NodeLayout: `nodeId` (%v) must fit in `nodeIdSize` (%v) bits",
+			nodeId, l.nodeIdSize)
+	}
+	values := make(map[string]uint32, len(l.fields))
+	for i := len(l.fields) - 1; i >= 0; i-- {
+		e := l.fields[i]
+		values[e.Name] = nodeId & (1<<e.Size - 1)
+		nodeId >>= e.Size
+	}
+	return values, nil
+}
+
+// Splits the `nodeId` embedded in a SCRU64 ID into field values, assuming that
+// the ID was generated by a generator of the layout's `nodeIdSize`.
+func (l NodeLayout) DecodeId(id Id) map[string]uint32 {
+	values, err := l.Decode(id.NodeCtr() >> (nodeCtrSize - l.nodeIdSize))
+	if err != nil {
+		panic(err)
+	}
+	return values
+}
+
+// Returns the layout string representation.
+func (l NodeLayout) String() string {
+	var b strings.Builder
+	for i, e := range l.fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%v:%v", e.Name, e.Size)
+	}
+	return b.String()
+}
+
+// See encoding.TextUnmarshaler
+func (l *NodeLayout) UnmarshalText(text []byte) error {
+	if l == nil {
+		return fmt.Errorf("scru64.NodeLayout: method call on nil receiver")
+	}
+
+	var fields []NodeLayoutField
+	for i, part := range strings.Split(string(text), ",") {
+		name, size, ok := strings.Cut(part, ":")
+		if !ok {
+			return fmt.Errorf(
+				`scru64.NodeLayout: could not parse field %d %q (expected: e.g., "region:3")`,
+				i+1, part)
+		}
+		n, err := strconv.ParseUint(size, 10, 8)
+		if err != nil {
+			return fmt.Errorf(
+				"scru64.NodeLayout: invalid size %q of field %d %q", size, i+1, name)
+		}
+		fields = append(fields, NodeLayoutField{Name: name, Size: uint8(n)})
+	}
+
+	result, err := NewNodeLayout(fields...)
+	if err == nil {
+		*l = result
+	}
+	return err
+}
+
+// See encoding.TextMarshaler
+func (l NodeLayout) MarshalText() (text []byte, err error) {
+	return []byte(l.String()), nil
+}
@@ -0,0 +1,88 @@
+package scru64
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// Builds node specs from field values and decodes them back.
+func TestNodeLayout(t *testing.T) {
+	l, err := ParseNodeLayout("region:3,cluster:4,host:5")
+	assert(t, err == nil && l.NodeIdSize() == 12 && len(l.Fields()) == 3)
+	assert(t, l.String() == "region:3,cluster:4,host:5")
+
+	values := map[string]uint32{"region": 5, "cluster": 9, "host": 30}
+	n, err := l.NodeSpec(values)
+	assert(t, err == nil && n.NodeIdSize() == 12)
+	assert(t, n.NodeId() == 5<<9|9<<5|30)
+
+	decoded, err := l.Decode(n.NodeId())
+	assert(t, err == nil && len(decoded) == 3)
+	for k, v := range values {
+		assert(t, decoded[k] == v)
+	}
+
+	g := NewGenerator(n)
+	decoded = l.DecodeId(g.GenerateOrSleep())
+	for k, v := range values {
+		assert(t, decoded[k] == v)
+	}
+
+	// value validation
+	assert(t, l.Validate(values) == nil)
+	_, err = l.NodeSpec(map[string]uint32{"region": 8, "cluster": 9, "host": 30})
+	assert(t, err != nil)
+	_, err = l.NodeSpec(map[string]uint32{"region": 5, "cluster": 9})
+	assert(t, err != nil)
+	_, err = l.NodeSpec(map[string]uint32{"region": 5, "cluster": 9, "host": 30, "rack": 1})
+	assert(t, err != nil)
+	_, err = l.Decode(1 << 12)
+	assert(t, err != nil)
+}
+
+// Fails to create layouts from invalid fields.
+func TestNodeLayoutError(t *testing.T) {
+	var cases = []string{
+		"",
+		"region",
+		"region:",
+		"region:0",
+		"region:-1",
+		"region:+3",
+		"region:3,",
+		"region:3,region:4",
+		"1region:3",
+		"region:3,host:21",
+		"region:300",
+		"region: 3",
+	}
+
+	for _, e := range cases {
+		l, err := ParseNodeLayout(e)
+		assert(t, len(l.Fields()) == 0 && err != nil)
+	}
+
+	_, err := NewNodeLayout()
+	assert(t, err != nil)
+	_, err = NodeLayout{}.Decode(0)
+	assert(t, err != nil)
+	assert(t, NodeLayout{}.Validate(map[string]uint32{}) != nil)
+}
+
+// Supports serialization and deserialization.
+func TestNodeLayoutSerDe(t *testing.T) {
+	var x, y NodeLayout
+	x, _ = NewNodeLayout(NodeLayoutField{"region", 3}, NodeLayoutField{"host", 5})
+
+	actual, err := json.Marshal(x)
+	assert(t, string(actual) == `"region:3,host:5"` && err == nil)
+
+	err = json.Unmarshal(actual, &y)
+	assert(t, x.String() == y.String() && err == nil)
+
+	var _ fmt.Stringer = x
+	var _ encoding.TextUnmarshaler = &x
+	var _ encoding.TextMarshaler = x
+}
@@ -0,0 +1,161 @@
+package scru64
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Represents a registered range of nodes with human-readable metadata.
+type NodeRegistryEntry struct {
+	// The range of nodes.
+	Nodes NodeSpecSet `json:"nodes"`
+
+	// The name of the nodes (e.g., a host name or service name).
+	Name string `json:"name"`
+
+	// Arbitrary key-value labels.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Returns the half-open range of `nodeCtr` values covered by the entry.
+func (e *NodeRegistryEntry) nodeCtrRange() (uint32, uint32) {
+	counterSize := nodeCtrSize - e.Nodes.NodeIdSize()
+	return e.Nodes.First() << counterSize, (e.Nodes.Last() + 1) << counterSize
+}
+
+// A registry mapping ranges of nodes to human-readable metadata.
+//
+// Each entry covers the `nodeCtr` values of the IDs that its nodes can
+// generate, so entries of different `nodeIdSize` values can coexist as long as
+// their ranges do not overlap. Overlapping entries would mean that two nodes
+// could generate colliding IDs, and thus the registry rejects them.
+//
+// A registry is usually loaded from a JSON file containing an array of entries:
+//
+//	[
+//	  {"nodes": "40-47/8", "name": "ingest", "labels": {"dc": "tokyo"}},
+//	  {"nodes": "0x300/12", "name": "batch-1"}
+//	]
+//
+// This type is safe for concurrent use.
+type NodeRegistry struct {
+	lock sync.RWMutex
+
+	// entries sorted by the start of `nodeCtr` range
+	entries []NodeRegistryEntry
+}
+
+// Creates an empty registry.
+func NewNodeRegistry() *NodeRegistry {
+	return &NodeRegistry{}
+}
+
+// Reads a registry from a JSON file.
+//
+// This function returns a non-nil error if the file cannot be read, if it is
+// not a valid JSON array of entries, or if any entries overlap.
+func LoadNodeRegistry(path string) (*NodeRegistry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("scru64.NodeRegistry: could not open file: %w", err)
+	}
+	defer f.Close()
+	return ReadNodeRegistry(f)
+}
+
+// Reads a registry from a JSON array of entries.
+//
+// This function returns a non-nil error if the input is not a valid JSON array
+// of entries or if any entries overlap.
+func ReadNodeRegistry(r io.Reader) (*NodeRegistry, error) {
+	var entries []NodeRegistryEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("scru64.NodeRegistry: could not decode JSON: %w", err)
+	}
+
+	registry := NewNodeRegistry()
+	for i, e := range entries {
+		if e.Nodes.Len() == 0 {
+			return nil, fmt.Errorf(
+				"scru64.NodeRegistry: entry %d (%q) has no \"nodes\"", i, e.Name)
+		}
+		if err := registry.Register(e); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Adds an entry to the registry.
+//
+// This method returns a non-nil error if the entry has an empty range or if it
+// overlaps an existing entry.
+func (r *NodeRegistry) Register(entry NodeRegistryEntry) error {
+	if entry.Nodes.Len() == 0 {
+		return fmt.Errorf("scru64.NodeRegistry: entry %q has empty range", entry.Name)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	start, end := entry.nodeCtrRange()
+	i := sort.Search(len(r.entries), func(i int) bool {
+		_, e := r.entries[i].nodeCtrRange()
+		return e > start
+	})
+	if i < len(r.entries) {
+		if s, _ := r.entries[i].nodeCtrRange(); s < end {
+			return fmt.Errorf(
+				"scru64.NodeRegistry: entry %q (%v) overlaps entry %q (%v)",
+				entry.Name, entry.Nodes, r.entries[i].Name, r.entries[i].Nodes)
+		}
+	}
+
+	r.entries = append(r.entries, NodeRegistryEntry{})
+	copy(r.entries[i+1:], r.entries[i:])
+	r.entries[i] = entry
+	return nil
+}
+
+// Returns a copy of the entries sorted by the range of IDs they cover.
+func (r *NodeRegistry) Entries() []NodeRegistryEntry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return append([]NodeRegistryEntry(nil), r.entries...)
+}
+
+// Finds the entry of the node that could have generated the ID.
+func (r *NodeRegistry) Lookup(id Id) (NodeRegistryEntry, bool) {
+	nodeCtr := id.NodeCtr()
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	i := sort.Search(len(r.entries), func(i int) bool {
+		_, e := r.entries[i].nodeCtrRange()
+		return e > nodeCtr
+	})
+	if i < len(r.entries) {
+		if s, _ := r.entries[i].nodeCtrRange(); s <= nodeCtr {
+			return r.entries[i], true
+		}
+	}
+	return NodeRegistryEntry{}, false
+}
+
+// Decodes the fields of the ID and annotates it with the registered node that
+// could have generated it.
+//
+// If a matching entry is found, its `nodeIdSize` is used to split the
+// `nodeCtr` field; otherwise, this method behaves like `id.Inspect(0)`.
+func (r *NodeRegistry) Inspect(id Id) IdInfo {
+	entry, ok := r.Lookup(id)
+	if !ok {
+		return id.Inspect(0)
+	}
+	info := id.Inspect(entry.Nodes.NodeIdSize())
+	info.Node = &entry
+	return info
+}
@@ -0,0 +1,80 @@
+package scru64
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Loads entries and annotates IDs with registered nodes.
+func TestNodeRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.json")
+	os.WriteFile(path, []byte(`[
+		{"nodes": "40-47/8", "name": "ingest", "labels": {"dc": "tokyo"}},
+		{"nodes": "0x300/12", "name": "batch-1"},
+		{"nodes": "1/1", "name": "upper-half"}
+	]`), 0o644)
+
+	r, err := LoadNodeRegistry(path)
+	assert(t, err == nil && len(r.Entries()) == 3)
+
+	n, _ := NewNodeSpecWithNodeId(42, 8)
+	x := NewGenerator(n).GenerateOrSleep()
+	info := r.Inspect(x)
+	assert(t, info.Node != nil && info.Node.Name == "ingest" && info.Node.Labels["dc"] == "tokyo")
+	assert(t, info.NodeIdSize == 8 && info.NodeId == 42)
+
+	n, _ = NewNodeSpecWithNodeId(0x300, 12)
+	x = NewGenerator(n).GenerateOrSleep()
+	info = r.Inspect(x)
+	assert(t, info.Node != nil && info.Node.Name == "batch-1" && info.NodeId == 0x300)
+
+	n, _ = NewNodeSpecWithNodeId(0x301, 12)
+	x = NewGenerator(n).GenerateOrSleep()
+	_, ok := r.Lookup(x)
+	assert(t, !ok)
+	info = r.Inspect(x)
+	assert(t, info.Node == nil && info.NodeIdSize == 0)
+
+	n, _ = NewNodeSpecWithNodeId(40000, 16)
+	x = NewGenerator(n).GenerateOrSleep()
+	entry, ok := r.Lookup(x)
+	assert(t, ok && entry.Name == "upper-half")
+}
+
+// Detects overlapping registrations.
+func TestNodeRegistryOverlap(t *testing.T) {
+	r := NewNodeRegistry()
+	register := func(nodes string, name string) error {
+		s, err := ParseNodeSpecSet(nodes)
+		assert(t, err == nil)
+		return r.Register(NodeRegistryEntry{Nodes: s, Name: name})
+	}
+
+	assert(t, register("40-47/8", "a") == nil)
+	assert(t, register("48/8", "b") == nil)
+	assert(t, register("39/8", "c") == nil)
+	assert(t, register("0x300/10", "d") == nil)
+
+	// same range, sub-range, super-range, and partial overlap
+	err := register("40-47/8", "x")
+	assert(t, err != nil && strings.Contains(err.Error(), `"a"`))
+	err = register("0x2a1/12", "x")
+	assert(t, err != nil && strings.Contains(err.Error(), `"a"`))
+	err = register("1/3", "x")
+	assert(t, err != nil)
+	err = register("47-48/8", "x")
+	assert(t, err != nil)
+	assert(t, r.Register(NodeRegistryEntry{Name: "empty"}) != nil)
+	assert(t, len(r.Entries()) == 4)
+
+	_, err = ReadNodeRegistry(strings.NewReader(`[{"nodes": "40-47/8", "name": "a"}, {"nodes": "44/8", "name": "b"}]`))
+	assert(t, err != nil)
+	_, err = ReadNodeRegistry(strings.NewReader(`[{"name": "a"}]`))
+	assert(t, err != nil)
+	_, err = ReadNodeRegistry(strings.NewReader(`[{"nodes": "40-47", "name": "a"}]`))
+	assert(t, err != nil)
+	_, err = LoadNodeRegistry(filepath.Join(t.TempDir(), "missing.json"))
+	assert(t, err != nil)
+}
@@ -4,29 +4,38 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 )
 
 // The unified format string for `nodeIdSize` range errors.
 const fmtNodeIdSizeError = "scru64.NodeSpec: `nodeIdSize` (%v) must range from 1 to 23"
 
-// A lazy initialization holder of the compiled regular expression representing
-// the node spec syntax.
+// A lazy initialization holder of the compiled regular expressions
+// representing the node spec syntax.
 var reNodeSpecHolder struct {
-	once  sync.Once
-	inner *regexp.Regexp
+	once       sync.Once
+	nodePrev   *regexp.Regexp
+	nodeId     *regexp.Regexp
+	nodeIdSize *regexp.Regexp
 }
 
 // Represents a node configuration specifier used to build a [Generator].
 //
 // A `NodeSpec` is usually expressed as a node spec string, which starts with a
-// decimal `nodeId`, a hexadecimal `nodeId` prefixed by "0x", or a 12-digit
-// `nodePrev` SCRU64 ID value, followed by a slash and a decimal `nodeIdSize`
-// value ranging from 1 to 23 (e.g., "42/8", "0xb00/12", "0u2r85hm2pt3/16"). The
-// first and second forms create a fresh new generator with the given `nodeId`,
-// while the third form constructs one that generates subsequent SCRU64 IDs to
-// the `nodePrev`. See also [the usage notes] in the SCRU64 spec for tips and
-// techniques to design node configurations.
+// decimal `nodeId`, a hexadecimal `nodeId` prefixed by "0x", a binary `nodeId`
+// prefixed by "0b", or a 12-digit `nodePrev` SCRU64 ID value, followed by a
+// slash and a decimal `nodeIdSize` value ranging from 1 to 23 (e.g., "42/8",
+// "0xb00/12", "0b1011/8", "0u2r85hm2pt3/16"). The first three forms create a
+// fresh new generator with the given `nodeId`, while the fourth form constructs
+// one that generates subsequent SCRU64 IDs to the `nodePrev`. A binary `nodeId`
+// with exactly 10 digits (e.g., "0b1000000000/10") is rejected as ambiguous
+// because it is also a valid 12-digit `nodePrev`; add a leading zero to the
+// digits (e.g., "0b01000000000/10") to express the `nodeId`. Alternatively, a
+// `nodeId` can be composed of named field values followed by an at sign and a
+// [NodeLayout] string (e.g., "region=2,host=9@region:3,host:5"), in which case
+// the layout determines the `nodeIdSize`. See also [the usage notes] in the
+// SCRU64 spec for tips and techniques to design node configurations.
 //
 // [the usage notes]: https://github.com/scru64/spec#informative-usage-notes
 type NodeSpec struct {
@@ -124,42 +133,137 @@ func (n *NodeSpec) UnmarshalText(text []byte) error {
 		return fmt.Errorf("scru64.NodeSpec: method call on nil receiver")
 	}
 
+	result, err := parseNodeSpec(string(text))
+	if err == nil {
+		*n = result
+	}
+	return err
+}
+
+// Compiles the regular expressions representing the node spec syntax.
+func compileNodeSpecRegexps() {
 	reNodeSpecHolder.once.Do(func() {
-		reNodeSpecHolder.inner = regexp.MustCompile(
-			`^(?:([0-9A-Za-z]{12})|([0-9]{1,8})|0[Xx]([0-9A-Fa-f]{1,6}))/([0-9]{1,3})$`)
+		reNodeSpecHolder.nodePrev = regexp.MustCompile(`^[0-9A-Za-z]{12}$`)
+		reNodeSpecHolder.nodeId = regexp.MustCompile(
+			`^(?:([0-9]{1,8})|0[Xx]([0-9A-Fa-f]{1,6})|0[Bb]([01]{1,23}))$`)
+		reNodeSpecHolder.nodeIdSize = regexp.MustCompile(`^[0-9]{1,3}$`)
 	})
+}
 
-	var m [][]byte = reNodeSpecHolder.inner.FindSubmatch(text)
-	if m == nil {
-		return fmt.Errorf(
-			`scru64.NodeSpec: could not parse string as node spec (expected: e.g., "42/8", "0xb00/12", "0u2r85hm2pt3/16")`)
+// Builds a node spec syntax error that points out the offending part.
+func newNodeSpecSyntaxError(value string, format string, args ...any) error {
+	return fmt.Errorf(
+		`scru64.NodeSpec: could not parse %q as node spec: %v (expected: e.g., "42/8", "0xb00/12", "0b1011/8", "0u2r85hm2pt3/16", "region=2,host=9@region:3,host:5")`,
+		value, fmt.Sprintf(format, args...))
+}
+
+// Parses a node spec string.
+func parseNodeSpec(value string) (NodeSpec, error) {
+	compileNodeSpecRegexps()
+	if fields, layout, ok := strings.Cut(value, "@"); ok {
+		return parseNodeSpecFields(value, fields, layout)
 	}
 
-	nodeIdSize, _ := strconv.ParseUint(string(m[4]), 10, 32)
-	if nodeIdSize > 0xff {
-		return fmt.Errorf(fmtNodeIdSizeError, nodeIdSize)
+	head, size, ok := strings.Cut(value, "/")
+	if !ok {
+		return NodeSpec{}, newNodeSpecSyntaxError(value, `missing "/<nodeIdSize>" part`)
+	}
+	nodeIdSize, err := parseNodeIdSizePart(size)
+	if err != nil {
+		return NodeSpec{}, newNodeSpecSyntaxError(value, "%v", err)
 	}
 
-	var result NodeSpec
-	var err error
-	if m[1] != nil {
+	if reNodeSpecHolder.nodePrev.MatchString(head) {
+		if reNodeSpecHolder.nodeId.MatchString(head) {
+			return NodeSpec{}, newNodeSpecSyntaxError(value,
+				"ambiguous part %q matches both binary `nodeId` and `nodePrev` (add a leading zero to the binary digits)",
+				head)
+		}
 		var nodePrev Id
-		_ = nodePrev.UnmarshalText(m[1])
-		result, err = NewNodeSpecWithNodePrev(nodePrev, uint8(nodeIdSize))
-	} else if m[2] != nil {
-		nodeId, _ := strconv.ParseUint(string(m[2]), 10, 32)
-		result, err = NewNodeSpecWithNodeId(uint32(nodeId), uint8(nodeIdSize))
-	} else if m[3] != nil {
-		nodeId, _ := strconv.ParseUint(string(m[3]), 16, 32)
-		result, err = NewNodeSpecWithNodeId(uint32(nodeId), uint8(nodeIdSize))
+		_ = nodePrev.UnmarshalText([]byte(head))
+		return NewNodeSpecWithNodePrev(nodePrev, nodeIdSize)
+	}
+	nodeId, err := parseNodeIdPart(head)
+	if err != nil {
+		return NodeSpec{}, newNodeSpecSyntaxError(value, "%v", err)
+	}
+	return NewNodeSpecWithNodeId(nodeId, nodeIdSize)
+}
+
+// Parses the `nodeIdSize` part following the slash of a node spec string.
+//
+// The returned error describes the offending part without a prefix so that
+// callers can wrap it in their respective syntax errors.
+func parseNodeIdSizePart(part string) (uint8, error) {
+	compileNodeSpecRegexps()
+	if !reNodeSpecHolder.nodeIdSize.MatchString(part) {
+		return 0, fmt.Errorf(
+			"invalid `nodeIdSize` part %q (expected: 1 to 3 decimal digits)", part)
+	}
+	nodeIdSize, _ := strconv.ParseUint(part, 10, 32)
+	if nodeIdSize > 0xff || nodeIdSize == 0 || nodeIdSize >= uint64(nodeCtrSize) {
+		return 0, fmt.Errorf(
+			"`nodeIdSize` part %q out of range (expected: 1 to 23)", part)
+	}
+	return uint8(nodeIdSize), nil
+}
+
+// Parses a decimal, hexadecimal, or binary `nodeId` part of a node spec string.
+//
+// The returned error describes the offending part without a prefix so that
+// callers can wrap it in their respective syntax errors.
+func parseNodeIdPart(part string) (uint32, error) {
+	compileNodeSpecRegexps()
+	m := reNodeSpecHolder.nodeId.FindStringSubmatch(part)
+	if m == nil {
+		return 0, fmt.Errorf("invalid `nodeId` part %q", part)
+	}
+
+	var nodeId uint64
+	if m[1] != "" {
+		nodeId, _ = strconv.ParseUint(m[1], 10, 32)
+	} else if m[2] != "" {
+		nodeId, _ = strconv.ParseUint(m[2], 16, 32)
+	} else if m[3] != "" {
+		nodeId, _ = strconv.ParseUint(m[3], 2, 32)
 	} else {
 		panic("unreachable")
 	}
+	return uint32(nodeId), nil
+}
+
+// Parses the named field form of a node spec string (e.g.,
+// "region=2,host=9@region:3,host:5").
+func parseNodeSpecFields(value string, fields string, layout string) (NodeSpec, error) {
+	l, err := ParseNodeLayout(layout)
+	if err != nil {
+		return NodeSpec{}, newNodeSpecSyntaxError(
+			value, "invalid layout part %q: %v", layout, err)
+	}
 
-	if err == nil {
-		*n = result
+	values := map[string]uint32{}
+	for _, e := range strings.Split(fields, ",") {
+		name, v, ok := strings.Cut(e, "=")
+		if !ok {
+			return NodeSpec{}, newNodeSpecSyntaxError(
+				value, `invalid field part %q (expected: "<name>=<value>")`, e)
+		} else if _, ok := values[name]; ok {
+			return NodeSpec{}, newNodeSpecSyntaxError(
+				value, "duplicate field part %q", e)
+		}
+		fieldValue, err := parseNodeIdPart(v)
+		if err != nil {
+			return NodeSpec{}, newNodeSpecSyntaxError(
+				value, "invalid value %q of field %q", v, name)
+		}
+		values[name] = fieldValue
 	}
-	return err
+
+	n, err := l.NodeSpec(values)
+	if err != nil {
+		return NodeSpec{}, newNodeSpecSyntaxError(value, "%v", err)
+	}
+	return n, nil
 }
 
 // See encoding.TextMarshaler
@@ -179,3 +283,66 @@ func (n *NodeSpec) Scan(src any) error {
 		return fmt.Errorf("scru64.NodeSpec: Scan: unsupported type conversion")
 	}
 }
+
+// Creates a child [NodeSpec] by appending `extraBits` bits holding `index` to
+// the `nodeId`.
+//
+// The child's `nodeId` is `n.NodeId() << extraBits | index` and its
+// `nodeIdSize` is `n.NodeIdSize() + extraBits`, so the IDs generated by any
+// child are a subset of the ones the parent could generate. This allows a
+// central team to delegate a sub-range of `nodeId` values to each datacenter,
+// host, or process. The `nodePrev` of the receiver, if any, is not inherited.
+//
+// This method returns a non-nil error if `extraBits` is zero, if the child's
+// `nodeIdSize` exceeds 23, or if `index` does not fit in `extraBits` bits.
+func (n NodeSpec) Child(index uint32, extraBits uint8) (NodeSpec, error) {
+	if extraBits == 0 || uint(n.NodeIdSize())+uint(extraBits) >= uint(nodeCtrSize) {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: `nodeIdSize` (%v) plus `extraBits` (%v) must range from %v to 23",
+			n.NodeIdSize(), extraBits, n.NodeIdSize()+1)
+	} else if index >= (1 << extraBits) {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: `index` (%v) must fit in `extraBits` (%v) bits",
+			index, extraBits)
+	}
+	return NewNodeSpecWithNodeId(n.NodeId()<<extraBits|index, n.NodeIdSize()+extraBits)
+}
+
+// Divides the receiver into all the `2^extraBits` children created by
+// [NodeSpec.Child], in ascending order of `nodeId`.
+//
+// This method returns a non-nil error if `extraBits` is zero or if the
+// children's `nodeIdSize` exceeds 23.
+func (n NodeSpec) Split(extraBits uint8) ([]NodeSpec, error) {
+	if _, err := n.Child(0, extraBits); err != nil {
+		return nil, err
+	}
+	children := make([]NodeSpec, 1<<extraBits)
+	for i := range children {
+		children[i], _ = n.Child(uint32(i), extraBits)
+	}
+	return children, nil
+}
+
+// Returns the parent [NodeSpec] that is `extraBits` bits shorter than the
+// receiver, i.e., the inverse of [NodeSpec.Child].
+//
+// This method returns a non-nil error if `extraBits` is zero or not smaller
+// than the `nodeIdSize` of the receiver.
+func (n NodeSpec) Parent(extraBits uint8) (NodeSpec, error) {
+	if extraBits == 0 || extraBits >= n.NodeIdSize() {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: `extraBits` (%v) must range from 1 to %v",
+			extraBits, n.NodeIdSize()-1)
+	}
+	return NewNodeSpecWithNodeId(n.NodeId()>>extraBits, n.NodeIdSize()-extraBits)
+}
+
+// Reports whether the receiver is a proper ancestor of `other`, i.e., whether
+// every ID `other` can generate is within the range of the receiver.
+func (n NodeSpec) IsAncestorOf(other NodeSpec) bool {
+	if n.NodeIdSize() >= other.NodeIdSize() {
+		return false
+	}
+	return other.NodeId()>>(other.NodeIdSize()-n.NodeIdSize()) == n.NodeId()
+}
@@ -0,0 +1,165 @@
+package scru64
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+	"os"
+)
+
+// Creates an instance of [NodeSpec] from the host name of the machine.
+//
+// The `nodeId` is the lowest `nodeIdSize` bits of the 32-bit FNV-1a hash of the
+// host name reported by the operating system, so that other implementations can
+// reproduce the same `nodeId` from the same host name. Since hashed `nodeId`
+// values may collide, see [NodeSpec.CollisionRisk] to evaluate whether the
+// `nodeIdSize` is large enough for the number of nodes in the realm.
+//
+// This function returns a non-nil error if the host name is unavailable or if
+// the `nodeIdSize` is zero or greater than 23.
+func NodeSpecFromHostname(nodeIdSize uint8) (NodeSpec, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: could not read host name: %w", err)
+	}
+	return nodeSpecFromHash([]byte(hostname), nodeIdSize)
+}
+
+// Creates an instance of [NodeSpec] from the lowest bits of an IP address of the
+// network interface named `iface`.
+//
+// The `nodeId` is the lowest `nodeIdSize` bits of the first IPv4 address of the
+// interface, or of the first IPv6 address if the interface has no IPv4 address.
+// If `iface` is empty, the first non-loopback interface that is up is used.
+// Unlike the hash-based constructors, this function never produces colliding
+// `nodeId` values as long as all the nodes in the realm have their addresses in
+// the same aligned block of `2^nodeIdSize` addresses (e.g., a /24 IPv4 subnet
+// for `nodeIdSize` of 8); otherwise, see [NodeSpec.CollisionRisk].
+//
+// This function returns a non-nil error if no suitable address is found or if
+// the `nodeIdSize` is zero or greater than 23.
+func NodeSpecFromIP(iface string, nodeIdSize uint8) (NodeSpec, error) {
+	ifi, err := findInterface(iface)
+	if err != nil {
+		return NodeSpec{}, err
+	}
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: could not read addresses of interface %q: %w",
+			ifi.Name, err)
+	}
+
+	var ip net.IP
+	for _, e := range addrs {
+		if ipNet, ok := e.(*net.IPNet); ok {
+			if v4 := ipNet.IP.To4(); v4 != nil {
+				ip = v4
+				break
+			} else if ip == nil {
+				ip = ipNet.IP
+			}
+		}
+	}
+	if ip == nil {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: interface %q has no IP address", ifi.Name)
+	}
+	return nodeSpecFromIP(ip, nodeIdSize)
+}
+
+// Creates an instance of [NodeSpec] from the hardware (MAC) address of the
+// network interface named `iface`.
+//
+// The `nodeId` is the lowest `nodeIdSize` bits of the 32-bit FNV-1a hash of the
+// raw bytes of the hardware address. If `iface` is empty, the first non-loopback
+// interface that is up is used. Since hashed `nodeId` values may collide, see
+// [NodeSpec.CollisionRisk] to evaluate whether the `nodeIdSize` is large enough
+// for the number of nodes in the realm.
+//
+// This function returns a non-nil error if the interface has no hardware
+// address or if the `nodeIdSize` is zero or greater than 23.
+func NodeSpecFromMAC(iface string, nodeIdSize uint8) (NodeSpec, error) {
+	ifi, err := findInterface(iface)
+	if err != nil {
+		return NodeSpec{}, err
+	}
+	if len(ifi.HardwareAddr) == 0 {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: interface %q has no hardware address", ifi.Name)
+	}
+	return nodeSpecFromHash(ifi.HardwareAddr, nodeIdSize)
+}
+
+// Returns the probability that at least two of `nodeCount` nodes are assigned
+// the same `nodeId` when each node picks a `nodeId` of `nodeIdSize` bits
+// uniformly at random, as the hash-based constructors effectively do.
+//
+// A collision means that the two nodes may generate duplicate IDs. The value is
+// the exact birthday-problem probability and is 1 if `nodeCount` exceeds the
+// number of distinct `nodeId` values.
+func (n NodeSpec) CollisionRisk(nodeCount int) float64 {
+	space := float64(uint64(1) << n.NodeIdSize())
+	if float64(nodeCount) > space {
+		return 1
+	}
+	var logNoCollision float64 = 0
+	for i := 1; i < nodeCount; i++ {
+		logNoCollision += math.Log1p(-float64(i) / space)
+	}
+	return -math.Expm1(logNoCollision)
+}
+
+// Looks up the network interface by name, or picks the first non-loopback
+// interface that is up if `name` is empty.
+func findInterface(name string) (*net.Interface, error) {
+	if name != "" {
+		ifi, err := net.InterfaceByName(name)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"scru64.NodeSpec: could not find interface %q: %w", name, err)
+		}
+		return ifi, nil
+	}
+
+	ifis, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"scru64.NodeSpec: could not list network interfaces: %w", err)
+	}
+	for i := range ifis {
+		if ifis[i].Flags&net.FlagUp != 0 && ifis[i].Flags&net.FlagLoopback == 0 {
+			return &ifis[i], nil
+		}
+	}
+	return nil, fmt.Errorf("scru64.NodeSpec: no non-loopback interface is up")
+}
+
+// Creates an instance of [NodeSpec] from the lowest bits of the FNV-1a hash of
+// `data`.
+func nodeSpecFromHash(data []byte, nodeIdSize uint8) (NodeSpec, error) {
+	if nodeIdSize == 0 || nodeIdSize >= nodeCtrSize {
+		return NodeSpec{}, fmt.Errorf(fmtNodeIdSizeError, nodeIdSize)
+	}
+	h := fnv.New32a()
+	h.Write(data)
+	return NewNodeSpecWithNodeId(h.Sum32()&(1<<nodeIdSize-1), nodeIdSize)
+}
+
+// Creates an instance of [NodeSpec] from the lowest bits of `ip`.
+func nodeSpecFromIP(ip net.IP, nodeIdSize uint8) (NodeSpec, error) {
+	if nodeIdSize == 0 || nodeIdSize >= nodeCtrSize {
+		return NodeSpec{}, fmt.Errorf(fmtNodeIdSizeError, nodeIdSize)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	if len(ip) < 4 {
+		return NodeSpec{}, fmt.Errorf("scru64.NodeSpec: invalid IP address %v", ip)
+	}
+	low := uint32(ip[len(ip)-4])<<24 | uint32(ip[len(ip)-3])<<16 |
+		uint32(ip[len(ip)-2])<<8 | uint32(ip[len(ip)-1])
+	return NewNodeSpecWithNodeId(low&(1<<nodeIdSize-1), nodeIdSize)
+}
@@ -0,0 +1,67 @@
+package scru64
+
+import (
+	"math"
+	"net"
+	"testing"
+)
+
+// Derives `nodeId` from the documented FNV-1a hash.
+func TestNodeSpecFromHash(t *testing.T) {
+	// FNV-1a("a") = 0xe40c292c
+	n, err := nodeSpecFromHash([]byte("a"), 8)
+	assert(t, err == nil && n.NodeId() == 0x2c && n.NodeIdSize() == 8)
+	n, err = nodeSpecFromHash([]byte("a"), 20)
+	assert(t, err == nil && n.NodeId() == 0xc292c && n.NodeIdSize() == 20)
+
+	_, err = nodeSpecFromHash([]byte("a"), 0)
+	assert(t, err != nil)
+	_, err = nodeSpecFromHash([]byte("a"), 24)
+	assert(t, err != nil)
+
+	n, err = NodeSpecFromHostname(16)
+	assert(t, err == nil && n.NodeIdSize() == 16)
+}
+
+// Derives `nodeId` from the lowest bits of IP addresses.
+func TestNodeSpecFromIP(t *testing.T) {
+	var cases = []struct {
+		ip         string
+		nodeIdSize uint8
+		nodeId     uint32
+	}{
+		{"10.0.1.42", 8, 42},
+		{"10.0.1.42", 12, 0x12a},
+		{"192.168.255.254", 16, 0xfffe},
+		{"2001:db8::1:2", 16, 2},
+		{"2001:db8::1:2", 20, 0x10002},
+	}
+
+	for _, e := range cases {
+		n, err := nodeSpecFromIP(net.ParseIP(e.ip), e.nodeIdSize)
+		assert(t, err == nil && n.NodeId() == e.nodeId && n.NodeIdSize() == e.nodeIdSize)
+	}
+
+	_, err := nodeSpecFromIP(net.ParseIP("10.0.1.42"), 24)
+	assert(t, err != nil)
+	_, err = nodeSpecFromIP(nil, 8)
+	assert(t, err != nil)
+	_, err = NodeSpecFromIP("no-such-interface", 8)
+	assert(t, err != nil)
+	_, err = NodeSpecFromMAC("no-such-interface", 8)
+	assert(t, err != nil)
+}
+
+// Computes birthday-problem collision probabilities.
+func TestCollisionRisk(t *testing.T) {
+	n8, _ := NewNodeSpecWithNodeId(0, 8)
+	assert(t, n8.CollisionRisk(0) == 0)
+	assert(t, n8.CollisionRisk(1) == 0)
+	assert(t, math.Abs(n8.CollisionRisk(2)-1.0/256) < 1e-12)
+	assert(t, n8.CollisionRisk(257) == 1)
+
+	n16, _ := NewNodeSpecWithNodeId(0, 16)
+	// approx. 50% at about 1.1774 * sqrt(65536) nodes
+	assert(t, math.Abs(n16.CollisionRisk(302)-0.5) < 0.01)
+	assert(t, n16.CollisionRisk(10) < n8.CollisionRisk(10))
+}
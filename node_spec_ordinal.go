@@ -0,0 +1,76 @@
+package scru64
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// A regular expression extracting the trailing ordinal of a StatefulSet pod
+// name (e.g., "17" of "ingest-17").
+var reOrdinal = regexp.MustCompile(`-([0-9]+)$`)
+
+// Creates an instance of [NodeSpec] from the ordinal of the Kubernetes
+// StatefulSet pod running the process.
+//
+// This function reads the pod name from the `HOSTNAME` environment variable,
+// which Kubernetes sets to the pod name (e.g., "ingest-17"), and reads an
+// optional decimal base offset from the `SCRU64_NODE_ID_BASE` environment
+// variable. See [NodeSpecFromOrdinalName] for how the `nodeId` is calculated.
+//
+// This function returns a non-nil error if either environment variable is
+// malformed or if the resulting `nodeId` does not fit in `nodeIdSize` bits.
+func NodeSpecFromOrdinal(nodeIdSize uint8) (NodeSpec, error) {
+	name, ok := os.LookupEnv("HOSTNAME")
+	if !ok {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: could not read pod name from HOSTNAME env var")
+	}
+
+	var base uint32 = 0
+	if value, ok := os.LookupEnv("SCRU64_NODE_ID_BASE"); ok {
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return NodeSpec{}, fmt.Errorf(
+				"scru64.NodeSpec: invalid SCRU64_NODE_ID_BASE env var %q", value)
+		}
+		base = uint32(n)
+	}
+	return NodeSpecFromOrdinalName(name, base, nodeIdSize)
+}
+
+// Creates an instance of [NodeSpec] from the trailing ordinal of a StatefulSet
+// pod name.
+//
+// The `nodeId` is the sum of `base` and the decimal ordinal following the last
+// hyphen of `name` (e.g., 17 of "ingest-17"). Assigning a distinct `base` to
+// each StatefulSet (e.g., 0 for "ingest" and 64 for "export") allows several
+// sets to share one realm as long as the ranges `[base, base + replicas)` do
+// not overlap.
+//
+// This function returns a non-nil error if `name` does not end with an ordinal,
+// if the `nodeIdSize` is zero or greater than 23, or if the resulting `nodeId`
+// does not fit in `nodeIdSize` bits.
+func NodeSpecFromOrdinalName(
+	name string, base uint32, nodeIdSize uint8) (NodeSpec, error) {
+	if nodeIdSize == 0 || nodeIdSize >= nodeCtrSize {
+		return NodeSpec{}, fmt.Errorf(fmtNodeIdSizeError, nodeIdSize)
+	}
+
+	m := reOrdinal.FindStringSubmatch(name)
+	if m == nil {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: pod name %q does not end with an ordinal (expected: e.g., \"ingest-17\")",
+			name)
+	}
+
+	maxNodeId := uint64(1)<<nodeIdSize - 1
+	ordinal, err := strconv.ParseUint(m[1], 10, 32)
+	if err != nil || uint64(base)+ordinal > maxNodeId {
+		return NodeSpec{}, fmt.Errorf(
+			"scru64.NodeSpec: ordinal (%v) of pod name %q plus base (%v) exceeds maximum `nodeId` (%v) for `nodeIdSize` (%v)",
+			m[1], name, base, maxNodeId, nodeIdSize)
+	}
+	return NewNodeSpecWithNodeId(base+uint32(ordinal), nodeIdSize)
+}
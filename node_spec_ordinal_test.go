@@ -0,0 +1,60 @@
+package scru64
+
+import (
+	"testing"
+)
+
+// Maps trailing ordinals of pod names to `nodeId` values.
+func TestNodeSpecFromOrdinalName(t *testing.T) {
+	var cases = []struct {
+		name       string
+		base       uint32
+		nodeIdSize uint8
+		nodeId     uint32
+	}{
+		{"ingest-0", 0, 8, 0},
+		{"ingest-17", 0, 8, 17},
+		{"ingest-17", 64, 8, 81},
+		{"my-app-v2-255", 0, 8, 255},
+		{"ingest-007", 0, 4, 7},
+		{"ingest-1023", 1024, 11, 2047},
+	}
+
+	for _, e := range cases {
+		n, err := NodeSpecFromOrdinalName(e.name, e.base, e.nodeIdSize)
+		assert(t, err == nil && n.NodeId() == e.nodeId && n.NodeIdSize() == e.nodeIdSize)
+	}
+
+	var errorCases = []struct {
+		name       string
+		base       uint32
+		nodeIdSize uint8
+	}{
+		{"ingest", 0, 8},
+		{"ingest-", 0, 8},
+		{"ingest-17a", 0, 8},
+		{"17", 0, 8},
+		{"ingest-256", 0, 8},
+		{"ingest-200", 56, 8},
+		{"ingest-99999999999", 0, 23},
+		{"ingest-1", 0, 0},
+		{"ingest-1", 0, 24},
+	}
+
+	for _, e := range errorCases {
+		n, err := NodeSpecFromOrdinalName(e.name, e.base, e.nodeIdSize)
+		assert(t, n == NodeSpec{} && err != nil)
+	}
+}
+
+// Reads pod name and base offset from environment vars.
+func TestNodeSpecFromOrdinal(t *testing.T) {
+	t.Setenv("HOSTNAME", "ingest-17")
+	t.Setenv("SCRU64_NODE_ID_BASE", "32")
+	n, err := NodeSpecFromOrdinal(8)
+	assert(t, err == nil && n.NodeId() == 49)
+
+	t.Setenv("SCRU64_NODE_ID_BASE", "x")
+	_, err = NodeSpecFromOrdinal(8)
+	assert(t, err != nil)
+}
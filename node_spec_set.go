@@ -0,0 +1,156 @@
+package scru64
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Represents a contiguous range of `nodeId` values of the same `nodeIdSize`,
+// used to describe a pool of nodes for allocators.
+//
+// A `NodeSpecSet` is usually expressed as a node spec set string, which
+// consists of the first and last `nodeId` (inclusive) separated by a hyphen,
+// followed by a slash and a decimal `nodeIdSize` (e.g., "40-47/8",
+// "0x28-0x2f/8"). Each `nodeId` can be written in any of the decimal,
+// hexadecimal, and binary forms accepted by [NodeSpec]. A single `nodeId`
+// without a hyphen (e.g., "42/8") denotes a set of one node.
+type NodeSpecSet struct {
+	first      uint32
+	last       uint32
+	nodeIdSize uint8
+}
+
+// Creates an instance of [NodeSpecSet] from the first and last `nodeId`
+// (inclusive) and `nodeIdSize` values.
+//
+// This function returns a non-nil error if the `nodeIdSize` is zero or greater
+// than 23, if `first` is greater than `last`, or if `last` does not fit in
+// `nodeIdSize` bits.
+func NewNodeSpecSet(first uint32, last uint32, nodeIdSize uint8) (NodeSpecSet, error) {
+	if nodeIdSize == 0 || nodeIdSize >= nodeCtrSize {
+		return NodeSpecSet{}, fmt.Errorf(fmtNodeIdSizeError, nodeIdSize)
+	} else if last >= (1 << nodeIdSize) {
+		return NodeSpecSet{}, fmt.Errorf(
+			"scru64.NodeSpecSet: last `nodeId` (%v) must fit in `nodeIdSize` (%v) bits",
+			last, nodeIdSize)
+	} else if first > last {
+		return NodeSpecSet{}, fmt.Errorf(
+			"scru64.NodeSpecSet: first `nodeId` (%v) must not be greater than last (%v)",
+			first, last)
+	}
+	return NodeSpecSet{first: first, last: last, nodeIdSize: nodeIdSize}, nil
+}
+
+// Creates an instance of [NodeSpecSet] from a node spec set string.
+//
+// This function returns a non-nil error if an invalid node spec set string is
+// passed.
+func ParseNodeSpecSet(value string) (NodeSpecSet, error) {
+	var s NodeSpecSet
+	return s, s.UnmarshalText([]byte(value))
+}
+
+// Returns the first `nodeId` in the set.
+func (s NodeSpecSet) First() uint32 {
+	return s.first
+}
+
+// Returns the last `nodeId` in the set.
+func (s NodeSpecSet) Last() uint32 {
+	return s.last
+}
+
+// Returns the `nodeIdSize` value.
+func (s NodeSpecSet) NodeIdSize() uint8 {
+	return s.nodeIdSize
+}
+
+// Returns the number of nodes in the set.
+func (s NodeSpecSet) Len() int {
+	if s.nodeIdSize == 0 {
+		return 0
+	}
+	return int(s.last-s.first) + 1
+}
+
+// Returns the `i`-th [NodeSpec] in the set, or panics if `i` is out of range.
+func (s NodeSpecSet) At(i int) NodeSpec {
+	if i < 0 || i >= s.Len() {
+		panic("index out of range")
+	}
+	n, _ := NewNodeSpecWithNodeId(s.first+uint32(i), s.nodeIdSize)
+	return n
+}
+
+// Returns all the node specs in the set in ascending order of `nodeId`.
+func (s NodeSpecSet) NodeSpecs() []NodeSpec {
+	result := make([]NodeSpec, s.Len())
+	for i := range result {
+		result[i] = s.At(i)
+	}
+	return result
+}
+
+// Reports whether the set contains a node spec with the same `nodeId` and
+// `nodeIdSize` as `n`.
+func (s NodeSpecSet) Contains(n NodeSpec) bool {
+	return n.NodeIdSize() == s.nodeIdSize &&
+		s.first <= n.NodeId() && n.NodeId() <= s.last
+}
+
+// Returns the node spec set string representation.
+func (s NodeSpecSet) String() string {
+	if s.first == s.last {
+		return fmt.Sprintf("%v/%v", s.first, s.nodeIdSize)
+	} else {
+		return fmt.Sprintf("%v-%v/%v", s.first, s.last, s.nodeIdSize)
+	}
+}
+
+// See encoding.TextUnmarshaler
+func (s *NodeSpecSet) UnmarshalText(text []byte) error {
+	if s == nil {
+		return fmt.Errorf("scru64.NodeSpecSet: method call on nil receiver")
+	}
+
+	value := string(text)
+	head, size, ok := strings.Cut(value, "/")
+	if !ok {
+		return newNodeSpecSetSyntaxError(value, `missing "/<nodeIdSize>" part`)
+	}
+	nodeIdSize, err := parseNodeIdSizePart(size)
+	if err != nil {
+		return newNodeSpecSetSyntaxError(value, "%v", err)
+	}
+
+	firstPart, lastPart, isRange := strings.Cut(head, "-")
+	first, err := parseNodeIdPart(firstPart)
+	if err != nil {
+		return newNodeSpecSetSyntaxError(value, "invalid first `nodeId` part %q", firstPart)
+	}
+	last := first
+	if isRange {
+		last, err = parseNodeIdPart(lastPart)
+		if err != nil {
+			return newNodeSpecSetSyntaxError(value, "invalid last `nodeId` part %q", lastPart)
+		}
+	}
+
+	result, err := NewNodeSpecSet(first, last, nodeIdSize)
+	if err == nil {
+		*s = result
+	}
+	return err
+}
+
+// See encoding.TextMarshaler
+func (s NodeSpecSet) MarshalText() (text []byte, err error) {
+	return []byte(s.String()), nil
+}
+
+// Builds a node spec set syntax error that points out the offending part.
+func newNodeSpecSetSyntaxError(value string, format string, args ...any) error {
+	return fmt.Errorf(
+		`scru64.NodeSpecSet: could not parse %q as node spec set: %v (expected: e.g., "40-47/8", "0x28-0x2f/8", "42/8")`,
+		value, fmt.Sprintf(format, args...))
+}
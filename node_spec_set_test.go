@@ -0,0 +1,91 @@
+package scru64
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Parses node spec set strings.
+func TestNodeSpecSet(t *testing.T) {
+	var cases = []struct {
+		nodeSpecSet string
+		canonical   string
+		first       uint32
+		last        uint32
+		nodeIdSize  uint8
+	}{
+		{"40-47/8", "40-47/8", 40, 47, 8},
+		{"0x28-0x2f/8", "40-47/8", 40, 47, 8},
+		{"0b101000-0b101111/8", "40-47/8", 40, 47, 8},
+		{"42/8", "42/8", 42, 42, 8},
+		{"42-42/8", "42/8", 42, 42, 8},
+		{"0-8388607/23", "0-8388607/23", 0, 8388607, 23},
+	}
+
+	for _, e := range cases {
+		s, err := ParseNodeSpecSet(e.nodeSpecSet)
+		assert(t, err == nil)
+		assert(t, s.First() == e.first && s.Last() == e.last && s.NodeIdSize() == e.nodeIdSize)
+		assert(t, s.Len() == int(e.last-e.first)+1)
+		assert(t, s.String() == e.canonical)
+	}
+
+	s, _ := NewNodeSpecSet(40, 43, 8)
+	specs := s.NodeSpecs()
+	assert(t, len(specs) == 4)
+	for i, e := range specs {
+		assert(t, e.NodeId() == 40+uint32(i) && e.NodeIdSize() == 8)
+		assert(t, s.Contains(e))
+		assert(t, s.At(i) == e)
+	}
+	n, _ := NewNodeSpecWithNodeId(44, 8)
+	assert(t, !s.Contains(n))
+	n, _ = NewNodeSpecWithNodeId(40, 9)
+	assert(t, !s.Contains(n))
+	assert(t, NodeSpecSet{}.Len() == 0)
+}
+
+// Fails to parse invalid node spec set strings.
+func TestNodeSpecSetError(t *testing.T) {
+	var cases = []struct {
+		nodeSpecSet string
+		part        string
+	}{
+		{"", `"/<nodeIdSize>"`},
+		{"40-47", `"/<nodeIdSize>"`},
+		{"40-47/", `""`},
+		{"40-47/0", `"0"`},
+		{"x-47/8", `first`},
+		{"40-/8", `last`},
+		{"40-47-50/8", `last`},
+		{"-47/8", `first`},
+		{"47-40/8", "must not be greater"},
+		{"40-256/8", "must fit"},
+		{" 40-47/8", `first`},
+	}
+
+	for _, e := range cases {
+		s, err := ParseNodeSpecSet(e.nodeSpecSet)
+		assert(t, s == NodeSpecSet{} && err != nil)
+		assert(t, err != nil && strings.Contains(err.Error(), e.part))
+	}
+}
+
+// Supports serialization and deserialization.
+func TestNodeSpecSetSerDe(t *testing.T) {
+	var x, y NodeSpecSet
+	x, _ = NewNodeSpecSet(40, 47, 8)
+
+	actual, err := json.Marshal(x)
+	assert(t, string(actual) == `"40-47/8"` && err == nil)
+
+	err = json.Unmarshal(actual, &y)
+	assert(t, x == y && err == nil)
+
+	var _ fmt.Stringer = x
+	var _ encoding.TextUnmarshaler = &x
+	var _ encoding.TextMarshaler = x
+}
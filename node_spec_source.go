@@ -0,0 +1,199 @@
+package scru64
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The error value a [NodeSpecSource] returns when it is not configured, which
+// makes [ResolveNodeSpec] move on to the next source.
+var ErrNodeSpecSourceUnavailable = errors.New("scru64.NodeSpecSource: not configured")
+
+// Represents a way to obtain the node configuration, such as an environment
+// variable or a file.
+//
+// See [DefaultNodeSpecSources] for the sources the global generator tries by
+// default.
+type NodeSpecSource interface {
+	// Returns a short human-readable name of the source for diagnostics (e.g.,
+	// "SCRU64_NODE_SPEC env var").
+	Name() string
+
+	// Returns the node spec provided by the source.
+	//
+	// This method returns an error that wraps [ErrNodeSpecSourceUnavailable] if
+	// the source is not configured, or any other non-nil error if the source is
+	// configured but fails to provide a valid node spec.
+	NodeSpec() (NodeSpec, error)
+}
+
+// Creates a [NodeSpecSource] from a name and a function.
+func NewNodeSpecSource(name string, f func() (NodeSpec, error)) NodeSpecSource {
+	return nodeSpecSourceFunc{name, f}
+}
+
+// The [NodeSpecSource] implementation returned by [NewNodeSpecSource].
+type nodeSpecSourceFunc struct {
+	name string
+	f    func() (NodeSpec, error)
+}
+
+func (s nodeSpecSourceFunc) Name() string {
+	return s.name
+}
+
+func (s nodeSpecSourceFunc) NodeSpec() (NodeSpec, error) {
+	return s.f()
+}
+
+// Returns the node spec provided by the first configured source of `sources`,
+// along with the name of the source.
+//
+// This function skips the sources that report [ErrNodeSpecSourceUnavailable]
+// but stops at the first source that is configured and fails, so that a
+// misconfiguration does not silently fall back to a less preferred source. It
+// returns a non-nil error in that case or if none of the sources is configured.
+func ResolveNodeSpec(sources []NodeSpecSource) (NodeSpec, string, error) {
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		nodeSpec, err := source.NodeSpec()
+		if err == nil {
+			return nodeSpec, source.Name(), nil
+		} else if !errors.Is(err, ErrNodeSpecSourceUnavailable) {
+			return NodeSpec{}, "", fmt.Errorf(
+				"scru64: could not read config from %v: %w", source.Name(), err)
+		}
+		names = append(names, source.Name())
+	}
+	return NodeSpec{}, "", fmt.Errorf(
+		"scru64: could not read config from any source (%v): not set",
+		strings.Join(names, ", "))
+}
+
+// Returns the chain of node spec sources that the global generator tries by
+// default, in the following order:
+//
+//  1. The `SCRU64_NODE_SPEC` environment variable containing a node spec string
+//     (e.g., "42/8").
+//  2. The file named by the `SCRU64_NODE_SPEC_FILE` environment variable
+//     containing a node spec string, such as a mounted Kubernetes ConfigMap or
+//     Secret.
+//  3. The Kubernetes StatefulSet ordinal (see [NodeSpecFromOrdinal]), if the
+//     `SCRU64_NODE_ID_SIZE` environment variable specifies the `nodeIdSize`.
+//  4. The hash of the host name (see [NodeSpecFromHostname]), if the
+//     `SCRU64_HOSTNAME_HASH_SIZE` environment variable specifies the
+//     `nodeIdSize`. Check [NodeSpec.CollisionRisk] before relying on this.
+//  5. A `nodeId` claimed from the node spec set in the `SCRU64_NODE_SPEC_SET`
+//     environment variable (e.g., "0-15/8") through a lock file in the
+//     directory named by the `SCRU64_NODE_ID_LOCK_DIR` environment variable
+//     (see [LockNodeIdInSet]). The lock is held for the life of the process
+//     and reused by later resolutions with the same directory and set, whereas
+//     a resolution with a different directory or set releases it after taking
+//     a new one.
+//
+// Each call returns a new slice, so callers may reorder or extend it before
+// passing it to `GlobalGenerator.SetNodeSpecSources`.
+func DefaultNodeSpecSources() []NodeSpecSource {
+	return []NodeSpecSource{
+		NewNodeSpecSource("SCRU64_NODE_SPEC env var", nodeSpecFromEnvVar),
+		NewNodeSpecSource("SCRU64_NODE_SPEC_FILE", nodeSpecFromFile),
+		NewNodeSpecSource("StatefulSet ordinal", func() (NodeSpec, error) {
+			nodeIdSize, err := nodeIdSizeFromEnv("SCRU64_NODE_ID_SIZE")
+			if err != nil {
+				return NodeSpec{}, err
+			}
+			return NodeSpecFromOrdinal(nodeIdSize)
+		}),
+		NewNodeSpecSource("hostname hash", func() (NodeSpec, error) {
+			nodeIdSize, err := nodeIdSizeFromEnv("SCRU64_HOSTNAME_HASH_SIZE")
+			if err != nil {
+				return NodeSpec{}, err
+			}
+			return NodeSpecFromHostname(nodeIdSize)
+		}),
+		NewNodeSpecSource("lock file", nodeSpecFromLockDir),
+	}
+}
+
+// Reads a node spec string from the `SCRU64_NODE_SPEC` environment variable.
+func nodeSpecFromEnvVar() (NodeSpec, error) {
+	value, ok := os.LookupEnv("SCRU64_NODE_SPEC")
+	if !ok {
+		return NodeSpec{}, ErrNodeSpecSourceUnavailable
+	}
+	return ParseNodeSpec(value)
+}
+
+// Reads a node spec string from the file named by the `SCRU64_NODE_SPEC_FILE`
+// environment variable.
+func nodeSpecFromFile() (NodeSpec, error) {
+	path, ok := os.LookupEnv("SCRU64_NODE_SPEC_FILE")
+	if !ok {
+		return NodeSpec{}, ErrNodeSpecSourceUnavailable
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NodeSpec{}, err
+	}
+	return ParseNodeSpec(strings.TrimSpace(string(data)))
+}
+
+// Claims a `nodeId` from the node spec set in the `SCRU64_NODE_SPEC_SET`
+// environment variable through a lock file in the `SCRU64_NODE_ID_LOCK_DIR`
+// directory.
+func nodeSpecFromLockDir() (NodeSpec, error) {
+	dir, ok := os.LookupEnv("SCRU64_NODE_ID_LOCK_DIR")
+	if !ok {
+		return NodeSpec{}, ErrNodeSpecSourceUnavailable
+	}
+	value, ok := os.LookupEnv("SCRU64_NODE_SPEC_SET")
+	if !ok {
+		return NodeSpec{}, fmt.Errorf("SCRU64_NODE_SPEC_SET env var not set")
+	}
+	set, err := ParseNodeSpecSet(value)
+	if err != nil {
+		return NodeSpec{}, err
+	}
+
+	held := &nodeSpecSourceLock
+	held.lock.Lock()
+	defer held.lock.Unlock()
+	if held.inner != nil && held.dir == dir && held.set == set {
+		return held.inner.NodeSpec(), nil
+	}
+	l, err := LockNodeIdInSet(dir, set)
+	if err != nil {
+		return NodeSpec{}, err
+	}
+	if held.inner != nil {
+		held.inner.Release()
+	}
+	held.inner, held.dir, held.set = l, dir, set
+	return l.NodeSpec(), nil
+}
+
+// The lock taken by the "lock file" source of [DefaultNodeSpecSources], kept in
+// one place so that repeated resolutions do not claim a new `nodeId` each.
+var nodeSpecSourceLock struct {
+	lock  sync.Mutex
+	inner *NodeIdLock
+	dir   string
+	set   NodeSpecSet
+}
+
+// Reads a `nodeIdSize` from an environment variable.
+func nodeIdSizeFromEnv(name string) (uint8, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return 0, ErrNodeSpecSourceUnavailable
+	}
+	nodeIdSize, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v env var: "+fmtNodeIdSizeError, name, value)
+	}
+	return uint8(nodeIdSize), nil
+}
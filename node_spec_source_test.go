@@ -0,0 +1,96 @@
+package scru64
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tries the default sources in order.
+func TestDefaultNodeSpecSources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "node-spec")
+	os.WriteFile(path, []byte("0xb00/12\n"), 0o644)
+
+	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	t.Setenv("SCRU64_NODE_SPEC_FILE", path)
+	t.Setenv("SCRU64_NODE_ID_SIZE", "12")
+	t.Setenv("HOSTNAME", "ingest-17")
+	t.Setenv("SCRU64_HOSTNAME_HASH_SIZE", "16")
+	t.Setenv("SCRU64_NODE_ID_LOCK_DIR", filepath.Join(dir, "locks"))
+	t.Setenv("SCRU64_NODE_SPEC_SET", "40-47/8")
+
+	n, source, err := ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err == nil && source == "SCRU64_NODE_SPEC env var")
+	assert(t, n.NodeId() == 42 && n.NodeIdSize() == 8)
+
+	os.Unsetenv("SCRU64_NODE_SPEC")
+	n, source, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err == nil && source == "SCRU64_NODE_SPEC_FILE" && n.NodeId() == 0xb00)
+
+	os.Unsetenv("SCRU64_NODE_SPEC_FILE")
+	n, source, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err == nil && source == "StatefulSet ordinal")
+	assert(t, n.NodeId() == 17 && n.NodeIdSize() == 12)
+
+	os.Unsetenv("SCRU64_NODE_ID_SIZE")
+	n, source, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	want, _ := NodeSpecFromHostname(16)
+	assert(t, err == nil && source == "hostname hash" && n == want)
+
+	os.Unsetenv("SCRU64_HOSTNAME_HASH_SIZE")
+	n, source, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("file locking is not supported")
+	}
+	assert(t, err == nil && source == "lock file")
+	assert(t, n.NodeId() == 40 && n.NodeIdSize() == 8)
+
+	// reuse the lock held by the previous resolution
+	n, _, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err == nil && n.NodeId() == 40)
+
+	// release the previous lock after taking one for another set
+	t.Setenv("SCRU64_NODE_SPEC_SET", "40-48/8")
+	n, _, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err == nil && n.NodeId() == 41)
+	set, _ := ParseNodeSpecSet("40-47/8")
+	l, err := LockNodeIdInSet(filepath.Join(dir, "locks"), set)
+	assert(t, err == nil && l.NodeSpec().NodeId() == 40)
+	l.Release()
+
+	os.Unsetenv("SCRU64_NODE_ID_LOCK_DIR")
+	_, _, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err != nil && strings.Contains(err.Error(), "not set"))
+}
+
+// Stops at the first source that is configured but fails.
+func TestResolveNodeSpecError(t *testing.T) {
+	t.Setenv("SCRU64_NODE_SPEC", "42/800")
+	t.Setenv("SCRU64_HOSTNAME_HASH_SIZE", "8")
+	_, _, err := ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err != nil && strings.Contains(err.Error(), "SCRU64_NODE_SPEC env var"))
+
+	os.Unsetenv("SCRU64_NODE_SPEC")
+	t.Setenv("SCRU64_NODE_SPEC_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, _, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err != nil && strings.Contains(err.Error(), "SCRU64_NODE_SPEC_FILE"))
+
+	os.Unsetenv("SCRU64_NODE_SPEC_FILE")
+	t.Setenv("SCRU64_NODE_ID_SIZE", "12")
+	t.Setenv("HOSTNAME", "ingest")
+	_, _, err = ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err != nil && strings.Contains(err.Error(), "StatefulSet ordinal"))
+
+	os.Unsetenv("SCRU64_NODE_ID_SIZE")
+	n, source, err := ResolveNodeSpec(DefaultNodeSpecSources())
+	assert(t, err == nil && source == "hostname hash" && n.NodeIdSize() == 8)
+
+	unavailable := NewNodeSpecSource("custom", func() (NodeSpec, error) {
+		return NodeSpec{}, ErrNodeSpecSourceUnavailable
+	})
+	_, _, err = ResolveNodeSpec([]NodeSpecSource{unavailable})
+	assert(t, err != nil && strings.Contains(err.Error(), "custom"))
+}
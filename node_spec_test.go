@@ -113,3 +113,109 @@ func TestNodeSpecInterfaces(t *testing.T) {
 	var _ encoding.TextMarshaler = x
 	var _ sql.Scanner = &x
 }
+
+// Subdivides node specs hierarchically.
+func TestNodeSpecHierarchy(t *testing.T) {
+	dc, _ := NewNodeSpecWithNodeId(2, 3)
+
+	host, err := dc.Child(5, 4)
+	assert(t, err == nil && host.NodeId() == 2<<4|5 && host.NodeIdSize() == 7)
+	proc, err := host.Child(1, 2)
+	assert(t, err == nil && proc.NodeId() == (2<<4|5)<<2|1 && proc.NodeIdSize() == 9)
+
+	parent, err := proc.Parent(2)
+	assert(t, err == nil && parent == host)
+	parent, err = proc.Parent(6)
+	assert(t, err == nil && parent == dc)
+
+	assert(t, dc.IsAncestorOf(host))
+	assert(t, dc.IsAncestorOf(proc))
+	assert(t, host.IsAncestorOf(proc))
+	assert(t, !proc.IsAncestorOf(host))
+	assert(t, !host.IsAncestorOf(host))
+	other, _ := dc.Child(6, 4)
+	assert(t, !other.IsAncestorOf(proc))
+
+	children, err := host.Split(2)
+	assert(t, err == nil && len(children) == 4)
+	for i, e := range children {
+		assert(t, e.NodeId() == host.NodeId()<<2|uint32(i) && e.NodeIdSize() == 9)
+		assert(t, host.IsAncestorOf(e))
+	}
+
+	// inherits no `nodePrev`
+	withPrev, _ := ParseNodeSpec("v0rbps7ay8ks/8")
+	child, err := withPrev.Child(3, 2)
+	assert(t, err == nil && child.NodePrev() == 0 && child.NodeId() == 68<<2|3)
+
+	// bounds checks against 23-bit limit
+	_, err = dc.Child(0, 0)
+	assert(t, err != nil)
+	_, err = dc.Child(16, 4)
+	assert(t, err != nil)
+	_, err = dc.Child(0, 21)
+	assert(t, err != nil)
+	_, err = dc.Child(0, 255)
+	assert(t, err != nil)
+	max, err := dc.Child(0, 20)
+	assert(t, err == nil && max.NodeIdSize() == 23)
+	_, err = dc.Split(21)
+	assert(t, err != nil)
+	_, err = dc.Parent(0)
+	assert(t, err != nil)
+	_, err = dc.Parent(3)
+	assert(t, err != nil)
+}
+
+// Parses binary and named field forms of node spec strings.
+func TestExtendedNodeSpecSyntax(t *testing.T) {
+	var cases = []struct {
+		nodeSpec   string
+		nodeId     uint32
+		nodeIdSize uint8
+	}{
+		{"0b1011/8", 11, 8},
+		{"0B0/1", 0, 1},
+		{"0b11111111111111111111111/23", 8388607, 23},
+		{"0b01000000000/10", 512, 10},
+		{"0B00000000001/10", 1, 10},
+		{"0b111111111/12", 511, 12},
+		{"region=2,host=9@region:3,host:5", 2<<5 | 9, 8},
+		{"host=9,region=2@region:3,host:5", 2<<5 | 9, 8},
+		{"region=0x2,host=0b1001@region:3,host:5", 2<<5 | 9, 8},
+		{"a=1@a:1", 1, 1},
+	}
+
+	for _, e := range cases {
+		n, err := ParseNodeSpec(e.nodeSpec)
+		assert(t, err == nil && n.NodeId() == e.nodeId && n.NodeIdSize() == e.nodeIdSize)
+	}
+
+	var errorCases = []struct {
+		nodeSpec string
+		part     string
+	}{
+		{"0b102/8", `"0b102"`},
+		{"0b/8", `"0b"`},
+		{"0b100000000/8", "must fit"},
+		{"0b1000000000/10", `ambiguous part "0b1000000000"`},
+		{"0B1111111111/12", `ambiguous part "0B1111111111"`},
+		{"0b0000000001/10", `ambiguous part "0b0000000001"`},
+		{"42", `"/<nodeIdSize>"`},
+		{"42/x", `"x"`},
+		{"42/24", `"24"`},
+		{"region=8,host=9@region:3,host:5", `field "region"`},
+		{"region=2@region:3,host:5", `field "host"`},
+		{"region=2,host=9,rack=1@region:3,host:5", `field "rack"`},
+		{"region=x,host=9@region:3,host:5", `"x"`},
+		{"region,host=9@region:3,host:5", `"region"`},
+		{"region=1,region=1@region:3", `"region=1"`},
+		{"region=2@region", `layout part "region"`},
+	}
+
+	for _, e := range errorCases {
+		n, err := ParseNodeSpec(e.nodeSpec)
+		assert(t, n == NodeSpec{} && err != nil)
+		assert(t, err != nil && strings.Contains(err.Error(), e.part))
+	}
+}
@@ -0,0 +1,206 @@
+package scru64
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// The maximum rate of borrowing future ticks that [Plan] tolerates when it
+// recommends a `nodeIdSize`.
+const planMaxBorrowRate = 0.01
+
+// Represents the workload that [Plan] evaluates.
+type PlanOptions struct {
+	// The number of nodes that generate IDs concurrently.
+	NodeCount int
+
+	// The expected peak number of IDs generated per second by each node.
+	PeakRate float64
+
+	// The duration for which each node sustains `PeakRate`. Zero selects one
+	// second.
+	BurstDuration time.Duration
+
+	// The overflow guard size of the default counter mode (see
+	// [NewDefaultCounterMode]). Zero selects the size that [NewGenerator] uses;
+	// set `NoOverflowGuard` to evaluate a counter mode without the guard.
+	OverflowGuardSize uint8
+
+	// Whether to evaluate the default counter mode without the overflow guard,
+	// overriding `OverflowGuardSize`.
+	NoOverflowGuard bool
+
+	// The `nodeIdSize` to evaluate, or zero to evaluate the recommended one.
+	NodeIdSize uint8
+}
+
+// Represents the capacity estimates of a `nodeIdSize` calculated by [Plan].
+//
+// The estimates assume generators created by [NewGenerator] or with the
+// default counter mode, whose counter starts at a random value in each tick.
+type CapacityPlan struct {
+	// The evaluated `nodeIdSize`.
+	NodeIdSize uint8 `json:"nodeIdSize"`
+
+	// The `counterSize` resulting from `NodeIdSize`.
+	CounterSize uint8 `json:"counterSize"`
+
+	// The overflow guard size of the counter mode.
+	OverflowGuardSize uint8 `json:"overflowGuardSize"`
+
+	// The maximum number of nodes that `NodeIdSize` accommodates.
+	MaxNodes uint32 `json:"maxNodes"`
+
+	// The expected peak number of IDs generated by a node per 256-millisecond
+	// `timestamp` tick.
+	IdsPerTick float64 `json:"idsPerTick"`
+
+	// The number of IDs that a node can generate within a tick without
+	// borrowing the next tick in the worst case, i.e., when the counter starts at
+	// the largest possible initial value.
+	MinTickCapacity uint32 `json:"minTickCapacity"`
+
+	// The average number of IDs that a node can generate within a tick without
+	// borrowing the next tick.
+	MeanTickCapacity float64 `json:"meanTickCapacity"`
+
+	// The expected fraction of ticks at the peak rate in which the counter
+	// overflows and the generator borrows the next tick.
+	BorrowRate float64 `json:"borrowRate"`
+
+	// The expected, not worst-case, lead of the `timestamp` over the wall clock
+	// at the end of a burst at the peak rate, capped at the default rollback
+	// allowance of 10 seconds, beyond which [Generator.Generate] fails and
+	// [Generator.GenerateOrSleep] sleeps.
+	//
+	// The estimate assumes that each tick holds `MeanTickCapacity` IDs on
+	// average, so the IDs in excess of it, generated at the sustained overflow
+	// rate of `IdsPerTick - MeanTickCapacity` per tick over `BurstDuration`, fill
+	// borrowed future ticks. It adds one tick if `BorrowRate` is positive,
+	// because a single tick can overflow even if the average capacity covers the
+	// peak rate.
+	ExpectedLead time.Duration `json:"expectedLead"`
+
+	// Whether the average tick capacity covers the peak rate. Otherwise, the
+	// lead keeps growing while the peak rate is sustained.
+	Sustainable bool `json:"sustainable"`
+
+	// The probability that a tick rewound by [Generator.GenerateOrReset] and
+	// reused at the peak rate produces an ID that duplicates one generated before
+	// the reset.
+	ResetDuplicateRisk float64 `json:"resetDuplicateRisk"`
+
+	// The recommended `nodeIdSize`: the largest one that keeps `BorrowRate`
+	// within 1%, leaving the most room for additional nodes, or, if no size
+	// does, the smallest one that accommodates `NodeCount` nodes.
+	RecommendedNodeIdSize uint8 `json:"recommendedNodeIdSize"`
+}
+
+// Estimates the capacity of SCRU64 ID generation for a workload and recommends
+// a `nodeIdSize` for it.
+//
+// This function returns a non-nil error if `NodeCount` is not positive or
+// exceeds 2^23, if `PeakRate` is negative or not finite, if `BurstDuration` is
+// negative, if `NodeIdSize` is greater than 23 or too small to accommodate
+// `NodeCount` nodes, or if `OverflowGuardSize` is greater than 23.
+func Plan(options PlanOptions) (CapacityPlan, error) {
+	if options.NodeCount <= 0 || options.NodeCount > 1<<23 {
+		return CapacityPlan{}, fmt.Errorf(
+			"scru64.Plan: `NodeCount` (%v) must range from 1 to 2^23", options.NodeCount)
+	} else if options.PeakRate < 0 || math.IsInf(options.PeakRate, 0) || math.IsNaN(options.PeakRate) {
+		return CapacityPlan{}, fmt.Errorf(
+			"scru64.Plan: `PeakRate` (%v) must be a non-negative number", options.PeakRate)
+	} else if options.BurstDuration < 0 {
+		return CapacityPlan{}, fmt.Errorf(
+			"scru64.Plan: `BurstDuration` (%v) must not be negative", options.BurstDuration)
+	} else if options.OverflowGuardSize >= nodeCtrSize {
+		return CapacityPlan{}, fmt.Errorf(
+			"scru64.Plan: `OverflowGuardSize` (%v) must be less than 24", options.OverflowGuardSize)
+	}
+
+	minNodeIdSize := uint8(bits.Len32(uint32(options.NodeCount - 1)))
+	if minNodeIdSize == 0 {
+		minNodeIdSize = 1
+	}
+	if options.NodeIdSize >= nodeCtrSize {
+		return CapacityPlan{}, fmt.Errorf(fmtNodeIdSizeError, options.NodeIdSize)
+	} else if options.NodeIdSize != 0 && options.NodeIdSize < minNodeIdSize {
+		return CapacityPlan{}, fmt.Errorf(
+			"scru64.Plan: `NodeIdSize` (%v) cannot accommodate %v nodes",
+			options.NodeIdSize, options.NodeCount)
+	}
+
+	recommended := minNodeIdSize
+	for nodeIdSize := uint8(nodeCtrSize - 1); nodeIdSize >= minNodeIdSize; nodeIdSize-- {
+		if evaluatePlan(options, nodeIdSize).BorrowRate <= planMaxBorrowRate {
+			recommended = nodeIdSize
+			break
+		}
+	}
+
+	nodeIdSize := options.NodeIdSize
+	if nodeIdSize == 0 {
+		nodeIdSize = recommended
+	}
+	plan := evaluatePlan(options, nodeIdSize)
+	plan.RecommendedNodeIdSize = recommended
+	return plan, nil
+}
+
+// Calculates the estimates of a `nodeIdSize` for a workload.
+func evaluatePlan(options PlanOptions, nodeIdSize uint8) CapacityPlan {
+	counterSize := nodeCtrSize - nodeIdSize
+	var overflowGuardSize uint8
+	if options.NoOverflowGuard {
+		overflowGuardSize = 0
+	} else if options.OverflowGuardSize > 0 {
+		overflowGuardSize = options.OverflowGuardSize
+	} else if nodeIdSize >= 20 {
+		// mirror NewGenerator
+		overflowGuardSize = 1
+	}
+
+	// the initial counter is uniformly distributed in [0, initRange)
+	counterRange := float64(uint32(1) << counterSize)
+	initRange := 1.0
+	if overflowGuardSize < counterSize {
+		initRange = float64(uint32(1) << (counterSize - overflowGuardSize))
+	}
+	minCapacity := counterRange - initRange + 1
+	idsPerTick := options.PeakRate * 0.256
+
+	plan := CapacityPlan{
+		NodeIdSize:        nodeIdSize,
+		CounterSize:       counterSize,
+		OverflowGuardSize: overflowGuardSize,
+		MaxNodes:          uint32(1) << nodeIdSize,
+		IdsPerTick:        idsPerTick,
+		MinTickCapacity:   uint32(minCapacity),
+		MeanTickCapacity:  counterRange - (initRange-1)/2,
+		BorrowRate:        clampUnit((idsPerTick - (counterRange - initRange)) / initRange),
+	}
+	plan.Sustainable = idsPerTick <= plan.MeanTickCapacity
+
+	if plan.BorrowRate > 0 {
+		burst := options.BurstDuration
+		if burst == 0 {
+			burst = time.Second
+		}
+		burstTicks := float64(burst.Milliseconds()) / 256
+		overflow := math.Max(0, idsPerTick-plan.MeanTickCapacity) * burstTicks
+		ticks := 1 + math.Ceil(overflow/plan.MeanTickCapacity)
+		plan.ExpectedLead = time.Duration(math.Min(ticks*256, 10_000)) * time.Millisecond
+	}
+
+	if idsPerTick > 0 {
+		plan.ResetDuplicateRisk = clampUnit((2*math.Ceil(idsPerTick) - 1) / initRange)
+	}
+	return plan
+}
+
+// Clamps a value to the range [0, 1].
+func clampUnit(x float64) float64 {
+	return math.Max(0, math.Min(1, x))
+}
@@ -0,0 +1,68 @@
+package scru64
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Estimates capacity of given and recommended node ID sizes.
+func TestPlan(t *testing.T) {
+	// 100 nodes generating 1000 IDs/s: 256 IDs per tick
+	p, err := Plan(PlanOptions{NodeCount: 100, PeakRate: 1000, NodeIdSize: 8})
+	assert(t, err == nil && p.NodeIdSize == 8 && p.CounterSize == 16 && p.OverflowGuardSize == 0)
+	assert(t, p.MaxNodes == 256 && p.IdsPerTick == 256 && p.MinTickCapacity == 1)
+	assert(t, p.MeanTickCapacity == 32768.5 && p.Sustainable)
+	assert(t, math.Abs(p.BorrowRate-256.0/65536) < 1e-9)
+	assert(t, p.ExpectedLead == 256*time.Millisecond)
+	assert(t, math.Abs(p.ResetDuplicateRisk-511.0/65536) < 1e-9)
+
+	// largest size with borrow rate <= 1%: 2^counterSize >= 25600
+	assert(t, p.RecommendedNodeIdSize == 9)
+	q, err := Plan(PlanOptions{NodeCount: 100, PeakRate: 1000})
+	assert(t, err == nil && q.NodeIdSize == 9 && q.RecommendedNodeIdSize == 9)
+
+	// overflow guard guarantees capacity
+	p, err = Plan(PlanOptions{NodeCount: 100, PeakRate: 1000, OverflowGuardSize: 4, NodeIdSize: 8})
+	assert(t, err == nil && p.MinTickCapacity == 61441 && p.BorrowRate == 0 && p.ExpectedLead == 0)
+
+	// overloaded nodes
+	p, err = Plan(PlanOptions{NodeCount: 4000, PeakRate: 10_000})
+	assert(t, err == nil && p.NodeIdSize == 12 && p.RecommendedNodeIdSize == 12)
+	assert(t, p.BorrowRate == 0.625 && !p.Sustainable && p.ResetDuplicateRisk == 1)
+	assert(t, p.OverflowGuardSize == 0 && p.MaxNodes == 4096)
+
+	// lead grows with overflow of (2560 - 2048.5) IDs per tick over the burst
+	assert(t, p.ExpectedLead == 2*256*time.Millisecond)
+	p, _ = Plan(PlanOptions{
+		NodeCount: 4000, PeakRate: 10_000, BurstDuration: 10 * time.Second})
+	assert(t, p.ExpectedLead == 11*256*time.Millisecond)
+	p, _ = Plan(PlanOptions{
+		NodeCount: 4000, PeakRate: 10_000, BurstDuration: time.Minute})
+	assert(t, p.ExpectedLead == 10*time.Second)
+	p, _ = Plan(PlanOptions{NodeCount: 1, PeakRate: 0, NodeIdSize: 21})
+	assert(t, p.OverflowGuardSize == 1 && p.MinTickCapacity == 5 && p.ExpectedLead == 0)
+	p, _ = Plan(PlanOptions{NodeCount: 1, PeakRate: 0, NodeIdSize: 21, NoOverflowGuard: true})
+	assert(t, p.OverflowGuardSize == 0 && p.MinTickCapacity == 1)
+	p, _ = Plan(PlanOptions{NodeCount: 1, PeakRate: 0, NodeIdSize: 21, OverflowGuardSize: 2})
+	assert(t, p.OverflowGuardSize == 2)
+}
+
+// Rejects invalid workloads.
+func TestPlanError(t *testing.T) {
+	for _, options := range []PlanOptions{
+		{NodeCount: 0, PeakRate: 1},
+		{NodeCount: 1<<23 + 1, PeakRate: 1},
+		{NodeCount: 1, PeakRate: -1},
+		{NodeCount: 1, PeakRate: math.NaN()},
+		{NodeCount: 1, PeakRate: 1, BurstDuration: -time.Second},
+		{NodeCount: 1, PeakRate: 1, OverflowGuardSize: 24},
+		{NodeCount: 1, PeakRate: 1, NodeIdSize: 24},
+		{NodeCount: 300, PeakRate: 1, NodeIdSize: 8},
+	} {
+		_, err := Plan(options)
+		assert(t, err != nil)
+	}
+	_, err := Plan(PlanOptions{NodeCount: 256, PeakRate: 1, NodeIdSize: 8})
+	assert(t, err == nil)
+}
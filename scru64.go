@@ -17,20 +17,23 @@ const nodeCtrSize uint8 = 24
 
 // Generates a new SCRU64 ID object using the global generator.
 //
-// By default, the global generator reads the node configuration from the
-// `SCRU64_NODE_SPEC` environment variable when a generator method is first
-// called, and it panics if it fails to do so. The node configuration is encoded
-// in a node spec string consisting of `nodeId` and `nodeIdSize` integers
-// separated by a slash (e.g., "42/8", "0xb00/12"; see [NodeSpec] for details).
-// You can configure the global generator differently by calling
-// `GlobalGenerator.Initialize()` before the default initializer is triggered.
+// By default, the global generator obtains the node configuration from the
+// sources returned by [DefaultNodeSpecSources], such as the `SCRU64_NODE_SPEC`
+// environment variable, when a generator method is first called, and it panics
+// if it fails to do so. The node configuration is typically encoded in a node
+// spec string consisting of `nodeId` and `nodeIdSize` integers separated by a
+// slash (e.g., "42/8", "0xb00/12"; see [NodeSpec] for details). You can
+// configure the global generator differently by calling
+// `GlobalGenerator.Initialize()` or `GlobalGenerator.SetNodeSpecSources()`
+// before the default initializer is triggered.
 //
 // This function usually returns a value immediately, but if not possible, it
 // sleeps and waits for the next timestamp tick.
 //
 // This function is thread-safe; multiple threads can call it concurrently.
 //
-// This function panics if the global generator is not properly configured.
+// This function panics if the global generator is not properly configured. See
+// [TryNew] for a non-panicking variant.
 func New() Id {
 	return GlobalGenerator.GenerateOrSleep()
 }
@@ -38,20 +41,51 @@ func New() Id {
 // Generates a new SCRU64 ID encoded in the 12-digit canonical string
 // representation using the global generator.
 //
-// By default, the global generator reads the node configuration from the
-// `SCRU64_NODE_SPEC` environment variable when a generator method is first
-// called, and it panics if it fails to do so. The node configuration is encoded
-// in a node spec string consisting of `nodeId` and `nodeIdSize` integers
-// separated by a slash (e.g., "42/8", "0xb00/12"; see [NodeSpec] for details).
-// You can configure the global generator differently by calling
-// `GlobalGenerator.Initialize()` before the default initializer is triggered.
+// By default, the global generator obtains the node configuration from the
+// sources returned by [DefaultNodeSpecSources], such as the `SCRU64_NODE_SPEC`
+// environment variable, when a generator method is first called, and it panics
+// if it fails to do so. The node configuration is typically encoded in a node
+// spec string consisting of `nodeId` and `nodeIdSize` integers separated by a
+// slash (e.g., "42/8", "0xb00/12"; see [NodeSpec] for details). You can
+// configure the global generator differently by calling
+// `GlobalGenerator.Initialize()` or `GlobalGenerator.SetNodeSpecSources()`
+// before the default initializer is triggered.
 //
 // This function usually returns a value immediately, but if not possible, it
 // sleeps and waits for the next timestamp tick.
 //
 // This function is thread-safe; multiple threads can call it concurrently.
 //
-// This function panics if the global generator is not properly configured.
+// This function panics if the global generator is not properly configured. See
+// [TryNewString] for a non-panicking variant.
 func NewString() string {
 	return New().String()
 }
+
+// Generates a new SCRU64 ID object using the global generator, or returns an
+// error if the global generator is not properly configured.
+//
+// This function works the same as [New] except that it returns the error
+// encountered by the default initializer of the global generator instead of
+// panicking. See also [InitializeFromEnv].
+func TryNew() (Id, error) {
+	if err := GlobalGenerator.Err(); err != nil {
+		return Id(0), err
+	}
+	return GlobalGenerator.GenerateOrSleep(), nil
+}
+
+// Generates a new SCRU64 ID encoded in the 12-digit canonical string
+// representation using the global generator, or returns an error if the global
+// generator is not properly configured.
+//
+// This function works the same as [NewString] except that it returns the error
+// encountered by the default initializer of the global generator instead of
+// panicking. See also [InitializeFromEnv].
+func TryNewString() (string, error) {
+	x, err := TryNew()
+	if err != nil {
+		return "", err
+	}
+	return x.String(), nil
+}
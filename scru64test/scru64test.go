@@ -0,0 +1,32 @@
+// Package scru64test provides utilities for testing code that uses the global
+// SCRU64 ID generator.
+package scru64test
+
+import (
+	"testing"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Installs `generator` as the global generator for the duration of the test and
+// restores the previous one on cleanup.
+//
+// This function also sets the `SCRU64_NODE_SPEC` environment variable to the
+// node spec of `generator` for the duration of the test, so that the
+// environment stays consistent with the installed generator. Like
+// `testing.T.Setenv`, this function cannot be used in parallel tests or tests
+// with parallel ancestors, because the global generator is shared by the
+// entire process.
+//
+// This function panics if `generator` is nil.
+func ReplaceGlobal(t testing.TB, generator *scru64.Generator) {
+	t.Helper()
+	if generator == nil {
+		panic("scru64test: ReplaceGlobal called with nil `generator`")
+	}
+	t.Setenv("SCRU64_NODE_SPEC", generator.NodeSpec().String())
+	prev := scru64.GlobalGenerator.Swap(generator)
+	t.Cleanup(func() {
+		scru64.GlobalGenerator.Swap(prev)
+	})
+}
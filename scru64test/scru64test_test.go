@@ -0,0 +1,59 @@
+package scru64test
+
+import (
+	"testing"
+
+	"github.com/scru64/go-scru64"
+)
+
+// Installs and restores the global generator.
+func TestReplaceGlobal(t *testing.T) {
+	t.Setenv("SCRU64_NODE_SPEC", "42/8")
+	if scru64.New().NodeCtr()>>16 != 42 {
+		t.Fatal("unexpected default global generator")
+	}
+
+	t.Run("replaced", func(t *testing.T) {
+		ReplaceGlobal(t, scru64.NewGeneratorParsing("0xb00/12"))
+		if n := scru64.GlobalGenerator.NodeId(); n != 0xb00 {
+			t.Fatalf("unexpected nodeId: %v", n)
+		}
+		if n := scru64.New().NodeCtr() >> 12; n != 0xb00 {
+			t.Fatalf("unexpected nodeId: %v", n)
+		}
+
+		t.Run("nested", func(t *testing.T) {
+			ReplaceGlobal(t, scru64.NewGeneratorParsing("7/4"))
+			if n := scru64.GlobalGenerator.NodeId(); n != 7 {
+				t.Fatalf("unexpected nodeId: %v", n)
+			}
+		})
+
+		if n := scru64.GlobalGenerator.NodeId(); n != 0xb00 {
+			t.Fatalf("unexpected nodeId: %v", n)
+		}
+	})
+
+	if n := scru64.GlobalGenerator.NodeId(); n != 42 {
+		t.Fatalf("unexpected nodeId: %v", n)
+	}
+}
+
+// Resets to uninitialized state when restoring nil.
+func TestReplaceGlobalUninitialized(t *testing.T) {
+	prev := scru64.GlobalGenerator.Swap(nil)
+	defer scru64.GlobalGenerator.Swap(prev)
+
+	t.Run("replaced", func(t *testing.T) {
+		ReplaceGlobal(t, scru64.NewGeneratorParsing("1/1"))
+		if n := scru64.GlobalGenerator.NodeId(); n != 1 {
+			t.Fatalf("unexpected nodeId: %v", n)
+		}
+	})
+
+	// default initializer runs again with restored environment
+	t.Setenv("SCRU64_NODE_SPEC", "255/8")
+	if n := scru64.GlobalGenerator.NodeId(); n != 255 {
+		t.Fatalf("unexpected nodeId: %v", n)
+	}
+}
@@ -0,0 +1,157 @@
+package sqlalloc
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// An in-process fake driver that understands only the statements issued by the
+// allocator and keeps a single lease table in memory.
+type fakeDriver struct {
+	lock sync.Mutex
+	rows map[int64]fakeRow
+
+	// if non-nil, called with the lock held before each INSERT, returning the
+	// error to fail the statement with, if any
+	beforeInsert func(nodeId int64) error
+}
+
+type fakeRow struct {
+	owner     string
+	expiresAt int64
+}
+
+var fakeDriverSeq struct {
+	lock sync.Mutex
+	n    int
+}
+
+// Opens a new database backed by a fresh fake driver.
+func openFakeDB() (*sql.DB, *fakeDriver) {
+	d := &fakeDriver{rows: map[int64]fakeRow{}}
+	fakeDriverSeq.lock.Lock()
+	name := fmt.Sprintf("sqlalloc-fake-%d", fakeDriverSeq.n)
+	fakeDriverSeq.n++
+	fakeDriverSeq.lock.Unlock()
+	sql.Register(name, d)
+	db, _ := sql.Open(name, "")
+	return db, d
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{d}, nil
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.d, query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+var (
+	reFakeSelect  = regexp.MustCompile(`^SELECT node_id, expires_at FROM \w+$`)
+	reFakeInsert  = regexp.MustCompile(`^INSERT INTO \w+ \(node_id, owner, expires_at\) VALUES \((\?|\$1), (\?|\$2), (\?|\$3)\)$`)
+	reFakeClaim   = regexp.MustCompile(`^UPDATE \w+ SET owner = \S+, expires_at = \S+ WHERE node_id = \S+ AND expires_at <= \S+$`)
+	reFakeRenew   = regexp.MustCompile(`^UPDATE \w+ SET expires_at = \S+ WHERE node_id = \S+ AND owner = \S+ AND expires_at > \S+$`)
+	reFakeRelease = regexp.MustCompile(`^UPDATE \w+ SET owner = \S+, expires_at = \S+ WHERE node_id = \S+ AND owner = \S+$`)
+)
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int {
+	if strings.Contains(s.query, "$") {
+		return strings.Count(s.query, "$")
+	}
+	return strings.Count(s.query, "?")
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.lock.Lock()
+	defer s.d.lock.Unlock()
+	switch {
+	case reFakeInsert.MatchString(s.query):
+		nodeId := args[0].(int64)
+		if s.d.beforeInsert != nil {
+			if err := s.d.beforeInsert(nodeId); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := s.d.rows[nodeId]; ok {
+			return nil, errors.New("unique constraint violation")
+		}
+		s.d.rows[nodeId] = fakeRow{args[1].(string), args[2].(int64)}
+		return driver.RowsAffected(1), nil
+	case reFakeClaim.MatchString(s.query):
+		nodeId := args[2].(int64)
+		if e, ok := s.d.rows[nodeId]; ok && e.expiresAt <= args[3].(int64) {
+			s.d.rows[nodeId] = fakeRow{args[0].(string), args[1].(int64)}
+			return driver.RowsAffected(1), nil
+		}
+		return driver.RowsAffected(0), nil
+	case reFakeRenew.MatchString(s.query):
+		nodeId := args[1].(int64)
+		if e, ok := s.d.rows[nodeId]; ok && e.owner == args[2].(string) && e.expiresAt > args[3].(int64) {
+			s.d.rows[nodeId] = fakeRow{e.owner, args[0].(int64)}
+			return driver.RowsAffected(1), nil
+		}
+		return driver.RowsAffected(0), nil
+	case reFakeRelease.MatchString(s.query):
+		nodeId := args[2].(int64)
+		if e, ok := s.d.rows[nodeId]; ok && e.owner == args[3].(string) {
+			s.d.rows[nodeId] = fakeRow{args[0].(string), args[1].(int64)}
+			return driver.RowsAffected(1), nil
+		}
+		return driver.RowsAffected(0), nil
+	}
+	return nil, fmt.Errorf("unsupported statement: %v", s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !reFakeSelect.MatchString(s.query) {
+		return nil, fmt.Errorf("unsupported query: %v", s.query)
+	}
+	s.d.lock.Lock()
+	defer s.d.lock.Unlock()
+	var rows [][2]int64
+	for k, v := range s.d.rows {
+		rows = append(rows, [2]int64{k, v.expiresAt})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct {
+	rows [][2]int64
+	pos  int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"node_id", "expires_at"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	dest[0], dest[1] = r.rows[r.pos][0], r.rows[r.pos][1]
+	r.pos++
+	return nil
+}
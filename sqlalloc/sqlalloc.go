@@ -0,0 +1,310 @@
+// Package sqlalloc implements a `nodeId` allocator for SCRU64 ID generators
+// backed by a lease table in a SQL database.
+//
+// An [Allocator] claims a `nodeId` by inserting or conditionally updating a row
+// of the lease table through `database/sql`, so that services sharing a
+// database can obtain distinct `nodeId` values without a coordination service.
+// Each row records the `nodeId`, an owner token, and an expiry timestamp in
+// Unix milliseconds. A `nodeId` becomes claimable again only after its expiry
+// plus the maximum lead of generator timestamps over the wall clock has
+// passed, so that a new holder never collides with the IDs generated by the
+// former holder.
+//
+// The allocator relies on the local clock of each host, so the hosts sharing a
+// lease table should keep their clocks synchronized.
+package sqlalloc
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/scru64/go-scru64"
+)
+
+// The error value returned by [Lease.Renew] when the lease has expired or been
+// taken over.
+var ErrLeaseLost = errors.New("sqlalloc.Lease: lease lost")
+
+// Represents the placeholder syntax of a SQL driver.
+type Dialect int
+
+const (
+	// Uses "?" placeholders (e.g., MySQL and SQLite).
+	DialectQuestion Dialect = iota
+
+	// Uses "$1", "$2", ... placeholders (e.g., PostgreSQL).
+	DialectDollar
+)
+
+// The default lifetime of a lease without renewal.
+const DefaultTTL = 30 * time.Second
+
+// The default maximum amount of time by which a generator's timestamp can lead
+// the wall clock, which corresponds to the default rollback allowance of
+// [scru64.Generator] plus one timestamp tick.
+const DefaultMaxLead = 10_000*time.Millisecond + 256*time.Millisecond
+
+// A regular expression representing the accepted table names.
+var reTableName = regexp.MustCompile(`^[A-Za-z_][0-9A-Za-z_]*(?:\.[A-Za-z_][0-9A-Za-z_]*)?$`)
+
+// A `nodeId` allocator backed by a lease table.
+//
+// This structure must be instantiated by [NewAllocator]. The lease table must
+// be created beforehand using the statement returned by [Allocator.DDL].
+type Allocator struct {
+	// Returns the current time. Defaults to `time.Now`; tests may replace it.
+	Now func() time.Time
+
+	db         *sql.DB
+	table      string
+	dialect    Dialect
+	nodeIdSize uint8
+	ttl        time.Duration
+	maxLead    time.Duration
+}
+
+// Creates a new allocator of `nodeIdSize`-bit `nodeId` values using the lease
+// table named `table` in `db`.
+//
+// `ttl` is the lifetime of a lease without renewal, and `maxLead` is the
+// maximum amount of time by which generator timestamps can lead the wall clock
+// (see [DefaultTTL] and [DefaultMaxLead]).
+//
+// This function returns a non-nil error if `nodeIdSize` is zero or greater than
+// 23, if `table` is not a plain (optionally schema-qualified) identifier, or if
+// `ttl` is not positive.
+func NewAllocator(db *sql.DB, table string, dialect Dialect, nodeIdSize uint8,
+	ttl time.Duration, maxLead time.Duration) (*Allocator, error) {
+	if _, err := scru64.NewNodeSpecWithNodeId(0, nodeIdSize); err != nil {
+		return nil, err
+	} else if !reTableName.MatchString(table) {
+		return nil, fmt.Errorf("sqlalloc.Allocator: invalid table name %q", table)
+	} else if ttl <= 0 {
+		return nil, fmt.Errorf("sqlalloc.Allocator: `ttl` (%v) must be positive", ttl)
+	} else if maxLead < 0 {
+		return nil, fmt.Errorf("sqlalloc.Allocator: `maxLead` (%v) must not be negative", maxLead)
+	}
+	return &Allocator{
+		Now:        time.Now,
+		db:         db,
+		table:      table,
+		dialect:    dialect,
+		nodeIdSize: nodeIdSize,
+		ttl:        ttl,
+		maxLead:    maxLead,
+	}, nil
+}
+
+// Returns the DDL statement that creates the lease table.
+//
+// The statement uses portable column types and `IF NOT EXISTS`, which most
+// databases accept.
+func (a *Allocator) DDL() string {
+	return "CREATE TABLE IF NOT EXISTS " + a.table + " (\n" +
+		"  node_id INTEGER NOT NULL PRIMARY KEY,\n" +
+		"  owner VARCHAR(64) NOT NULL,\n" +
+		"  expires_at BIGINT NOT NULL\n" +
+		")"
+}
+
+// Replaces "?" placeholders in `query` according to the dialect.
+func (a *Allocator) query(query string) string {
+	if a.dialect != DialectDollar {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Claims a free `nodeId` and returns the lease.
+//
+// This method prefers a `nodeId` that has never been recorded in the lease
+// table and otherwise takes over the smallest `nodeId` whose lease has expired
+// and left quarantine. It returns a non-nil error if all the `nodeId` values
+// are taken or if the database fails.
+func (a *Allocator) Acquire(ctx context.Context) (*Lease, error) {
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		now := a.Now()
+		expiresAt := now.Add(a.ttl).UnixMilli()
+		known, err := a.scan(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// try a `nodeId` absent from the table first
+		conflict := false
+		for nodeId := uint32(0); nodeId < (1 << a.nodeIdSize); nodeId++ {
+			if _, ok := known[nodeId]; ok {
+				continue
+			}
+			_, insertErr := a.db.ExecContext(ctx, a.query(
+				"INSERT INTO "+a.table+" (node_id, owner, expires_at) VALUES (?, ?, ?)"),
+				int64(nodeId), owner, expiresAt)
+			if insertErr == nil {
+				return a.newLease(nodeId, owner, expiresAt), nil
+			}
+
+			// retry only if another allocator has inserted the same `nodeId`, which
+			// is told from other failures by the presence of the row because the
+			// errors for unique key violations vary among drivers
+			if inserted, err := a.scan(ctx); err != nil {
+				return nil, err
+			} else if _, ok := inserted[nodeId]; !ok {
+				return nil, fmt.Errorf("sqlalloc.Allocator: could not claim `nodeId`: %w", insertErr)
+			}
+			conflict = true
+			break
+		}
+		if conflict {
+			continue
+		}
+
+		// take over an expired lease that has left quarantine
+		claimable := now.Add(-a.maxLead).UnixMilli()
+		for nodeId := uint32(0); nodeId < (1 << a.nodeIdSize); nodeId++ {
+			if e, ok := known[nodeId]; !ok || e > claimable {
+				continue
+			}
+			res, err := a.db.ExecContext(ctx, a.query(
+				"UPDATE "+a.table+" SET owner = ?, expires_at = ? WHERE node_id = ? AND expires_at <= ?"),
+				owner, expiresAt, int64(nodeId), claimable)
+			if err != nil {
+				return nil, fmt.Errorf("sqlalloc.Allocator: could not claim `nodeId`: %w", err)
+			}
+			if n, err := res.RowsAffected(); err != nil {
+				return nil, fmt.Errorf("sqlalloc.Allocator: could not claim `nodeId`: %w", err)
+			} else if n == 1 {
+				return a.newLease(nodeId, owner, expiresAt), nil
+			}
+		}
+		return nil, fmt.Errorf("sqlalloc.Allocator: all `nodeId` values are taken")
+	}
+	return nil, fmt.Errorf("sqlalloc.Allocator: could not claim `nodeId`: too many conflicts")
+}
+
+// Reads the expiry of every `nodeId` recorded in the lease table.
+func (a *Allocator) scan(ctx context.Context) (map[uint32]int64, error) {
+	rows, err := a.db.QueryContext(ctx, "SELECT node_id, expires_at FROM "+a.table)
+	if err != nil {
+		return nil, fmt.Errorf("sqlalloc.Allocator: could not read lease table: %w", err)
+	}
+	defer rows.Close()
+
+	known := map[uint32]int64{}
+	for rows.Next() {
+		var nodeId, expiresAt int64
+		if err := rows.Scan(&nodeId, &expiresAt); err != nil {
+			return nil, fmt.Errorf("sqlalloc.Allocator: could not read lease table: %w", err)
+		}
+		if 0 <= nodeId && nodeId < (1<<a.nodeIdSize) {
+			known[uint32(nodeId)] = expiresAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlalloc.Allocator: could not read lease table: %w", err)
+	}
+	return known, nil
+}
+
+// Creates a lease object for a claimed `nodeId`.
+func (a *Allocator) newLease(nodeId uint32, owner string, expiresAt int64) *Lease {
+	nodeSpec, _ := scru64.NewNodeSpecWithNodeId(nodeId, a.nodeIdSize)
+	return &Lease{
+		allocator: a,
+		nodeSpec:  nodeSpec,
+		owner:     owner,
+		expiresAt: time.UnixMilli(expiresAt),
+	}
+}
+
+// Represents a `nodeId` claimed by [Allocator.Acquire].
+//
+// The holder must call [Lease.Renew] well before [Lease.ExpiresAt] (e.g., every
+// one third of the TTL) and must stop generating IDs with the `nodeId` once the
+// lease has expired or renewal has failed with [ErrLeaseLost]. A lease is not
+// safe for concurrent use.
+type Lease struct {
+	allocator *Allocator
+	nodeSpec  scru64.NodeSpec
+	owner     string
+	expiresAt time.Time
+}
+
+// Returns the node configuration of the claimed `nodeId`, which can be passed to
+// [scru64.NewGenerator].
+func (l *Lease) NodeSpec() scru64.NodeSpec {
+	return l.nodeSpec
+}
+
+// Returns the time at which the lease expires unless renewed.
+func (l *Lease) ExpiresAt() time.Time {
+	return l.expiresAt
+}
+
+// Extends the lease by one TTL from now.
+//
+// This method returns an error wrapping [ErrLeaseLost] if the lease has already
+// expired or been taken over, or another non-nil error if the database fails.
+func (l *Lease) Renew(ctx context.Context) error {
+	a := l.allocator
+	now := a.Now()
+	expiresAt := now.Add(a.ttl).UnixMilli()
+	res, err := a.db.ExecContext(ctx, a.query(
+		"UPDATE "+a.table+" SET expires_at = ? WHERE node_id = ? AND owner = ? AND expires_at > ?"),
+		expiresAt, int64(l.nodeSpec.NodeId()), l.owner, now.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("sqlalloc.Lease: could not renew lease: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("sqlalloc.Lease: could not renew lease: %w", err)
+	} else if n != 1 {
+		return ErrLeaseLost
+	}
+	l.expiresAt = time.UnixMilli(expiresAt)
+	return nil
+}
+
+// Returns the `nodeId` to the pool after the quarantine period.
+//
+// The caller must stop generating IDs with the `nodeId` before calling this
+// method.
+func (l *Lease) Release(ctx context.Context) error {
+	a := l.allocator
+	_, err := a.db.ExecContext(ctx, a.query(
+		"UPDATE "+a.table+" SET owner = ?, expires_at = ? WHERE node_id = ? AND owner = ?"),
+		"", a.Now().UnixMilli(), int64(l.nodeSpec.NodeId()), l.owner)
+	if err != nil {
+		return fmt.Errorf("sqlalloc.Lease: could not release lease: %w", err)
+	}
+	return nil
+}
+
+// Generates a random owner token.
+func newOwner() (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", fmt.Errorf("sqlalloc.Allocator: could not generate owner token: %w", err)
+	}
+	return hex.EncodeToString(buf[:]), nil
+}
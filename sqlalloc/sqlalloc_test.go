@@ -0,0 +1,170 @@
+package sqlalloc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Claims distinct `nodeId` values, renews leases, and quarantines released ones.
+func TestAllocator(t *testing.T) {
+	for _, dialect := range []Dialect{DialectQuestion, DialectDollar} {
+		ctx := context.Background()
+		db, _ := openFakeDB()
+		now := time.UnixMilli(1_700_000_000_000)
+		a, err := NewAllocator(db, "scru64_leases", dialect, 2, 30*time.Second, DefaultMaxLead)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Now = func() time.Time { return now }
+
+		var leases []*Lease
+		for i := uint32(0); i < 4; i++ {
+			l, err := a.Acquire(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l.NodeSpec().NodeId() != i || l.NodeSpec().NodeIdSize() != 2 {
+				t.Fatalf("unexpected node spec: %v", l.NodeSpec())
+			}
+			leases = append(leases, l)
+		}
+		if _, err := a.Acquire(ctx); err == nil {
+			t.Fatal("expected error")
+		}
+
+		// released `nodeId` is claimable after quarantine
+		if err := leases[2].Release(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := a.Acquire(ctx); err == nil {
+			t.Fatal("expected error")
+		}
+		now = now.Add(DefaultMaxLead)
+		l, err := a.Acquire(ctx)
+		if err != nil || l.NodeSpec().NodeId() != 2 {
+			t.Fatalf("unexpected result: %v %v", l, err)
+		}
+		leases[2] = l
+
+		// renewed lease survives while others expire
+		now = now.Add(10 * time.Second)
+		if err := leases[0].Renew(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if !leases[0].ExpiresAt().Equal(now.Add(30 * time.Second)) {
+			t.Fatalf("unexpected expiry: %v", leases[0].ExpiresAt())
+		}
+		now = now.Add(20 * time.Second)
+		if err := leases[1].Renew(ctx); !errors.Is(err, ErrLeaseLost) {
+			t.Fatalf("expected ErrLeaseLost, got %v", err)
+		}
+		now = now.Add(DefaultMaxLead)
+		l, err = a.Acquire(ctx)
+		if err != nil || l.NodeSpec().NodeId() != 1 {
+			t.Fatalf("unexpected result: %v %v", l, err)
+		}
+
+		// former holder cannot renew or release taken-over lease
+		if err := leases[1].Renew(ctx); !errors.Is(err, ErrLeaseLost) {
+			t.Fatalf("expected ErrLeaseLost, got %v", err)
+		}
+		leases[1].Release(ctx)
+		if err := l.Renew(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Hands out unique `nodeId` values to concurrent allocators.
+func TestAllocatorConcurrent(t *testing.T) {
+	const nWorkers = 16
+	db, _ := openFakeDB()
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	seen := map[uint32]bool{}
+	for i := 0; i < nWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a, _ := NewAllocator(db, "scru64_leases", DialectQuestion, 8, DefaultTTL, DefaultMaxLead)
+			l, err := a.Acquire(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if seen[l.NodeSpec().NodeId()] {
+				t.Errorf("duplicate nodeId: %v", l.NodeSpec())
+			}
+			seen[l.NodeSpec().NodeId()] = true
+		}()
+	}
+	wg.Wait()
+}
+
+// Retries after losing an INSERT race but fails fast on other database errors.
+func TestAllocatorInsertFailure(t *testing.T) {
+	ctx := context.Background()
+	db, d := openFakeDB()
+	now := time.UnixMilli(1_700_000_000_000)
+	a, _ := NewAllocator(db, "scru64_leases", DialectQuestion, 2, DefaultTTL, DefaultMaxLead)
+	a.Now = func() time.Time { return now }
+
+	// `nodeId` 0 and 1 are live, 2 has expired, and 3 is absent
+	d.rows[0] = fakeRow{"a", now.Add(time.Minute).UnixMilli()}
+	d.rows[1] = fakeRow{"b", now.Add(time.Minute).UnixMilli()}
+	d.rows[2] = fakeRow{"c", now.Add(-time.Minute).UnixMilli()}
+
+	// a concurrent allocator inserts 3 first, so the expired 2 is taken over
+	d.beforeInsert = func(nodeId int64) error {
+		d.rows[nodeId] = fakeRow{"d", now.Add(time.Minute).UnixMilli()}
+		return nil
+	}
+	l, err := a.Acquire(ctx)
+	if err != nil || l.NodeSpec().NodeId() != 2 {
+		t.Fatalf("unexpected result: %v %v", l, err)
+	}
+
+	// a failure that leaves no row behind is not retried
+	delete(d.rows, 3)
+	errConn := errors.New("connection refused")
+	nCalls := 0
+	d.beforeInsert = func(int64) error {
+		nCalls++
+		return errConn
+	}
+	if _, err := a.Acquire(ctx); !errors.Is(err, errConn) || nCalls != 1 {
+		t.Fatalf("unexpected result: %v (%v calls)", err, nCalls)
+	}
+}
+
+// Generates DDL and rejects invalid configurations.
+func TestAllocatorConfig(t *testing.T) {
+	db, _ := openFakeDB()
+	a, err := NewAllocator(db, "public.scru64_leases", DialectDollar, 8, DefaultTTL, DefaultMaxLead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ddl := a.DDL(); !strings.HasPrefix(ddl, "CREATE TABLE IF NOT EXISTS public.scru64_leases (") {
+		t.Fatalf("unexpected DDL: %v", ddl)
+	}
+	if q := a.query("a = ? AND b = ?"); q != "a = $1 AND b = $2" {
+		t.Fatalf("unexpected query: %v", q)
+	}
+
+	if _, err := NewAllocator(db, "leases; DROP TABLE x", DialectQuestion, 8, DefaultTTL, 0); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := NewAllocator(db, "leases", DialectQuestion, 24, DefaultTTL, 0); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := NewAllocator(db, "leases", DialectQuestion, 8, 0, 0); err == nil {
+		t.Fatal("expected error")
+	}
+}